@@ -3,6 +3,8 @@ module github.com/llnw/terraform-provider-limelight
 go 1.13
 
 require (
+	github.com/hashicorp/hcl/v2 v2.0.0
 	github.com/hashicorp/terraform-plugin-sdk v1.9.0
 	github.com/llnw/llnw-sdk-go v1.0.4
+	github.com/zclconf/go-cty v1.2.1
 )
@@ -0,0 +1,129 @@
+package limelight
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/llnw/llnw-sdk-go"
+)
+
+// defaultAPIRequestTimeout bounds a request made outside of an operation with a timeout
+// of its own, unless http_timeout is set.
+const defaultAPIRequestTimeout = 30 * time.Second
+
+// apiClient sends signed requests to one of the APIs. The llnw-sdk-go clients send their
+// requests with http.DefaultTransport, which can only be configured for the whole
+// process, so the provider sends its requests itself, through the transport configured
+// on the provider.
+type apiClient struct {
+	Auth    *llnw.Auth
+	BaseUrl string
+
+	// transport sends the requests, http.DefaultTransport if nil.
+	transport http.RoundTripper
+	// readTimeout bounds the requests of reads, which aren't part of an operation with a
+	// timeout of its own.
+	readTimeout time.Duration
+	// limiter spaces out the requests, if not nil.
+	limiter *rateLimiter
+}
+
+// rateLimiter spaces out requests by at least interval. Unlike a time.Tick channel, it
+// doesn't keep a ticker running, and doesn't let a burst of requests through after a
+// quiet period.
+type rateLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// wait blocks until the next request may be sent.
+func (l *rateLimiter) wait() {
+	l.mu.Lock()
+	now := time.Now()
+	start := l.next
+	if start.Before(now) {
+		start = now
+	}
+	l.next = start.Add(l.interval)
+	l.mu.Unlock()
+
+	time.Sleep(start.Sub(now))
+}
+
+func newAPIClient(apiUser string, apiKey string, baseURL string) *apiClient {
+	return &apiClient{
+		Auth: &llnw.Auth{
+			APIUser: apiUser,
+			APIKey:  apiKey,
+		},
+		BaseUrl:     baseURL,
+		readTimeout: defaultAPIRequestTimeout,
+	}
+}
+
+func (c *apiClient) SetUserAgent(userAgent string) {
+	c.Auth.UserAgent = userAgent
+}
+
+// get reads url, bounded by the read timeout.
+func (c *apiClient) get(url string) ([]byte, *http.Response, error) {
+	return c.request(http.MethodGet, url, nil, c.readTimeout)
+}
+
+// request sends a signed request with request, if not nil, as its JSON body, and returns
+// the body of the response. Unlike the llnw-sdk-go clients, which always give up after 30
+// seconds, the request is bounded by timeout, so that an operation's timeout applies to
+// its requests. The body of an error response is included in the error and left in place
+// for classifyAPIError.
+func (c *apiClient) request(method string, url string, request interface{}, timeout time.Duration) ([]byte, *http.Response, error) {
+	var body []byte
+	var reader io.Reader
+	if request != nil {
+		var err error
+		if body, err = json.Marshal(request); err != nil {
+			return nil, nil, err
+		}
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	if request != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Auth.UserAgent != "" {
+		req.Header.Set("User-Agent", c.Auth.UserAgent)
+	}
+	signRequest(req, c.Auth.APIUser, c.Auth.APIKey, body)
+
+	if c.limiter != nil {
+		c.limiter.wait()
+	}
+
+	client := &http.Client{Transport: c.transport, Timeout: timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, resp, fmt.Errorf("non-2XX status code from API, got status %d: %s", resp.StatusCode, readErrorBody(resp))
+	}
+
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp, err
+	}
+	return respBody, resp, nil
+}
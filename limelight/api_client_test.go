@@ -0,0 +1,68 @@
+package limelight
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := &rateLimiter{interval: 50 * time.Millisecond}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limiter.wait()
+		}()
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("expected 4 requests to take at least 3 intervals, took %s", elapsed)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	start = time.Now()
+	limiter.wait()
+	if elapsed := time.Since(start); elapsed > 25*time.Millisecond {
+		t.Errorf("expected a request after a quiet period not to wait, waited %s", elapsed)
+	}
+	limiter.wait()
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("expected no burst after a quiet period, the second request waited %s", elapsed)
+	}
+}
+
+func TestConfigurationClientSharesRateLimiter(t *testing.T) {
+	var times []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, time.Now())
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	client := newConfigurationClient("user", "00", server.URL)
+	client.limiter.interval = 100 * time.Millisecond
+
+	if _, _, err := getServiceInstance(client, deliveryServiceKey, "uuid-1"); err != nil {
+		t.Fatal(err)
+	}
+	check := credentialsChecks(&Meta{ConfigurationClient: client, EdgeFunctionsClient: newEdgeFunctionsClient("user", "00", server.URL)}, "example")[0]
+	if err := validateCredentials([]credentialsCheck{check}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(times) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(times))
+	}
+	// The requests are sent at least an interval apart, but may reach the server closer
+	// together.
+	if gap := times[1].Sub(times[0]); gap < 80*time.Millisecond {
+		t.Errorf("expected the requests to be spaced by the client's limiter, got %s apart", gap)
+	}
+}
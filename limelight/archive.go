@@ -0,0 +1,310 @@
+package limelight
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxFunctionArchiveSize is the largest EdgeFunction archive that is read from a URL.
+const maxFunctionArchiveSize = 50 * 1024 * 1024
+
+// maxFunctionArchiveUncompressedSize is the largest total size of the files in an
+// EdgeFunction archive.
+const maxFunctionArchiveUncompressedSize = 250 * 1024 * 1024
+
+const functionArchiveDownloadTimeout = 5 * time.Minute
+
+// runtimeHandlerExtensions are the extensions of the file a handler's module can be in,
+// by runtime prefix. The handler of other runtimes isn't checked.
+var runtimeHandlerExtensions = map[string][]string{
+	"python": {".py"},
+	"node":   {".js", ".mjs", ".cjs"},
+}
+
+// isArchiveURL reports whether a function_archive is a URL rather than a local path.
+func isArchiveURL(location string) bool {
+	lower := strings.ToLower(location)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "file://")
+}
+
+// fetchArchive reads an EdgeFunction archive from a file://, http:// or https:// URL,
+// downloading it with transport, and verifies it against expectedSha256. Downloaded
+// archives are cached by hash, so the same archive is only downloaded once.
+func fetchArchive(location string, expectedSha256 string, transport http.RoundTripper) ([]byte, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid function_archive URL %s: %s", location, err)
+	}
+
+	if expectedSha256 == "" {
+		return nil, fmt.Errorf("function_sha256 must be known to load function_archive from %s", location)
+	}
+
+	if u.Scheme == "file" {
+		archive, err := readArchive(u.Path)
+		if err != nil {
+			return nil, err
+		}
+		return archive, verifyArchiveSha256(archive, expectedSha256, location)
+	}
+
+	if archive, ok := readCachedArchive(expectedSha256); ok {
+		log.Printf("[DEBUG] Using the cached EdgeFunction archive for %s", location)
+		return archive, nil
+	}
+
+	archive, err := downloadArchive(location, transport)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifyArchiveSha256(archive, expectedSha256, location); err != nil {
+		return nil, err
+	}
+
+	if err := writeCachedArchive(cachedArchivePath(expectedSha256), archive); err != nil {
+		log.Printf("[WARN] Failed to cache EdgeFunction archive %s: %s", location, err)
+	}
+
+	return archive, nil
+}
+
+func readArchive(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return readLimited(f, path)
+}
+
+func downloadArchive(location string, transport http.RoundTripper) ([]byte, error) {
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   functionArchiveDownloadTimeout,
+	}
+
+	log.Printf("[INFO] Downloading EdgeFunction archive from %s", location)
+	resp, err := client.Get(location)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading function_archive %s: %s", location, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error downloading function_archive %s: got status %d", location, resp.StatusCode)
+	}
+
+	return readLimited(resp.Body, location)
+}
+
+// readLimited reads an archive, failing if it is larger than maxFunctionArchiveSize.
+func readLimited(r io.Reader, location string) ([]byte, error) {
+	archive, err := ioutil.ReadAll(io.LimitReader(r, maxFunctionArchiveSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading function_archive %s: %s", location, err)
+	}
+
+	if len(archive) > maxFunctionArchiveSize {
+		return nil, fmt.Errorf("function_archive %s is larger than %d bytes", location, maxFunctionArchiveSize)
+	}
+
+	return archive, nil
+}
+
+func verifyArchiveSha256(archive []byte, expectedSha256 string, location string) error {
+	sum := sha256.Sum256(archive)
+	actualSha256 := hex.EncodeToString(sum[:])
+
+	if !strings.EqualFold(actualSha256, expectedSha256) {
+		return fmt.Errorf("function_archive %s has SHA256 %s, but function_sha256 is %s", location, actualSha256, expectedSha256)
+	}
+
+	return nil
+}
+
+// archiveCacheDir returns the directory downloaded archives are cached in. It can be
+// overridden with the LLNW_ARCHIVE_CACHE_DIR environment variable.
+func archiveCacheDir() string {
+	if dir := os.Getenv("LLNW_ARCHIVE_CACHE_DIR"); dir != "" {
+		return dir
+	}
+
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "terraform-provider-limelight", "edgefunctions")
+	}
+
+	return filepath.Join(os.TempDir(), "terraform-provider-limelight", "edgefunctions")
+}
+
+// cachedArchivePath returns the path an archive is cached at, by SHA256.
+func cachedArchivePath(sha256 string) string {
+	return filepath.Join(archiveCacheDir(), strings.ToLower(sha256)+".zip")
+}
+
+// readCachedArchive returns the cached archive with the given SHA256, if any.
+func readCachedArchive(sha256 string) ([]byte, bool) {
+	cachePath := cachedArchivePath(sha256)
+	archive, err := ioutil.ReadFile(cachePath)
+	if err != nil {
+		return nil, false
+	}
+
+	if err := verifyArchiveSha256(archive, sha256, cachePath); err != nil {
+		log.Printf("[WARN] Ignoring cached EdgeFunction archive %s with unexpected content", cachePath)
+		return nil, false
+	}
+
+	return archive, true
+}
+
+// deployedArchivePath returns the path the copy of the archive deployed to an
+// EdgeFunction is kept at. There is at most one copy per EdgeFunction.
+func deployedArchivePath(shortname string, name string) string {
+	return filepath.Join(archiveCacheDir(), "deployed", url.PathEscape(shortname), url.PathEscape(name)+".zip")
+}
+
+// keepDeployedArchive keeps a copy of the archive deployed to an EdgeFunction, in place
+// of the copy of the archive deployed before it, so that the next update can be rolled
+// back to it even when the file it was deployed from has since been overwritten.
+func keepDeployedArchive(shortname string, name string, archive []byte) {
+	if err := writeCachedArchive(deployedArchivePath(shortname, name), archive); err != nil {
+		log.Printf("[WARN] Failed to keep a copy of the archive deployed to EdgeFunction %s: %s", name, err)
+	}
+}
+
+// readDeployedArchive returns the copy of the archive deployed to an EdgeFunction, if it
+// has the given SHA256.
+func readDeployedArchive(shortname string, name string, sha256 string) ([]byte, bool) {
+	deployedPath := deployedArchivePath(shortname, name)
+	archive, err := ioutil.ReadFile(deployedPath)
+	if err != nil {
+		return nil, false
+	}
+
+	if verifyArchiveSha256(archive, sha256, deployedPath) != nil {
+		return nil, false
+	}
+
+	return archive, true
+}
+
+// removeDeployedArchive removes the copy of the archive deployed to an EdgeFunction once
+// it is deleted.
+func removeDeployedArchive(shortname string, name string) {
+	if err := os.Remove(deployedArchivePath(shortname, name)); err != nil && !os.IsNotExist(err) {
+		log.Printf("[WARN] Failed to remove the copy of the archive deployed to EdgeFunction %s: %s", name, err)
+	}
+}
+
+func writeCachedArchive(path string, archive []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".download-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(archive); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// validateFunctionArchive checks that an EdgeFunction archive is a zip file within the
+// size limits, which contains the module of the handler, of the form <module>.<function>,
+// and that the function is mentioned in the module.
+func validateFunctionArchive(archive []byte, handler string, runtime string) error {
+	if len(archive) > maxFunctionArchiveSize {
+		return fmt.Errorf("function_archive is %d bytes, larger than the limit of %d bytes", len(archive), maxFunctionArchiveSize)
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return fmt.Errorf("function_archive is not a valid zip file: %s", err)
+	}
+
+	var uncompressedSize uint64
+	files := make(map[string]*zip.File, len(r.File))
+	for _, f := range r.File {
+		uncompressedSize += f.UncompressedSize64
+		files[path.Clean(strings.TrimPrefix(f.Name, "./"))] = f
+	}
+	if uncompressedSize > maxFunctionArchiveUncompressedSize {
+		return fmt.Errorf("function_archive is %d bytes uncompressed, larger than the limit of %d bytes", uncompressedSize, maxFunctionArchiveUncompressedSize)
+	}
+
+	var extensions []string
+	for prefix, e := range runtimeHandlerExtensions {
+		if strings.HasPrefix(strings.ToLower(runtime), prefix) {
+			extensions = e
+		}
+	}
+	if extensions == nil {
+		return nil
+	}
+
+	i := strings.LastIndex(handler, ".")
+	if i <= 0 || i == len(handler)-1 {
+		return fmt.Errorf("handler %q must be of the form <module>.<function> for runtime %s", handler, runtime)
+	}
+	module, function := handler[:i], handler[i+1:]
+	if strings.HasPrefix(strings.ToLower(runtime), "python") {
+		module = strings.Replace(module, ".", "/", -1)
+	}
+
+	var candidates []string
+	for _, extension := range extensions {
+		candidates = append(candidates, module+extension)
+	}
+	if strings.HasPrefix(strings.ToLower(runtime), "python") {
+		candidates = append(candidates, module+"/__init__.py")
+	}
+
+	for _, candidate := range candidates {
+		f, ok := files[path.Clean(candidate)]
+		if !ok {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("error reading %s in function_archive: %s", f.Name, err)
+		}
+		source, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("error reading %s in function_archive: %s", f.Name, err)
+		}
+
+		if !regexp.MustCompile(`\b` + regexp.QuoteMeta(function) + `\b`).Match(source) {
+			return fmt.Errorf("handler function %s is not defined in %s in function_archive", function, f.Name)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("handler module %s not found in function_archive, expected one of: %s", module, strings.Join(candidates, ", "))
+}
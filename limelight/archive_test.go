@@ -0,0 +1,193 @@
+package limelight
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestFetchArchive_http(t *testing.T) {
+	archive := []byte("PK\x03\x04 not really a zip")
+	sum := sha256.Sum256(archive)
+	archiveSha256 := hex.EncodeToString(sum[:])
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	cacheDir, err := ioutil.TempDir("", "limelight-archive-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(cacheDir)
+	os.Setenv("LLNW_ARCHIVE_CACHE_DIR", cacheDir)
+	defer os.Unsetenv("LLNW_ARCHIVE_CACHE_DIR")
+
+	for i := 0; i < 2; i++ {
+		fetched, err := loadZipFile(server.URL+"/function.zip", archiveSha256, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if string(fetched) != string(archive) {
+			t.Fatalf("unexpected archive content %q", fetched)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("expected the archive to be downloaded once, got %d requests", requests)
+	}
+
+	if _, err := loadZipFile(server.URL+"/other.zip", hex.EncodeToString(make([]byte, 32)), nil); err == nil {
+		t.Errorf("expected a checksum mismatch error")
+	}
+}
+
+func TestFetchArchive_file(t *testing.T) {
+	dir, err := ioutil.TempDir("", "limelight-archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "function.zip")
+	archive := []byte("archive")
+	if err := ioutil.WriteFile(path, archive, 0600); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(archive)
+
+	if _, err := loadZipFile("file://"+path, hex.EncodeToString(sum[:]), nil); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if _, err := loadZipFile("file://"+path, "", nil); err == nil {
+		t.Errorf("expected an error without function_sha256")
+	}
+}
+
+// testZipArchive returns a zip archive of the given files, by name.
+func testZipArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestValidateFunctionArchive(t *testing.T) {
+	python := map[string]string{"hello_world.py": "def handler(req, context):\n    pass\n"}
+	pythonPackage := map[string]string{"app/__init__.py": "", "app/main.py": "async def handle(req, context):\n    pass\n"}
+	node := map[string]string{"src/index.mjs": "export const handler = async (req) => ({statusCode: 200})\n"}
+
+	cases := []struct {
+		name          string
+		archive       []byte
+		handler       string
+		runtime       string
+		expectedError string
+	}{
+		{"python", testZipArchive(t, python), "hello_world.handler", "python3", ""},
+		{"python package", testZipArchive(t, pythonPackage), "app.main.handle", "python3", ""},
+		{"node", testZipArchive(t, node), "src/index.handler", "nodejs", ""},
+		{"other runtime", testZipArchive(t, python), "anything", "go1.x", ""},
+		{"not a zip", []byte("not a zip"), "hello_world.handler", "python3", "not a valid zip file"},
+		{"too large", make([]byte, maxFunctionArchiveSize+1), "hello_world.handler", "python3", "larger than the limit"},
+		{"missing module", testZipArchive(t, python), "main.handler", "python3", "handler module main not found in function_archive, expected one of: main.py, main/__init__.py"},
+		{"missing function", testZipArchive(t, python), "hello_world.handle", "python3", "handler function handle is not defined in hello_world.py"},
+		{"module for another runtime", testZipArchive(t, python), "hello_world.handler", "nodejs", "handler module hello_world not found"},
+		{"malformed handler", testZipArchive(t, python), "handler", "python3", "must be of the form <module>.<function>"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateFunctionArchive(c.archive, c.handler, c.runtime)
+			if c.expectedError == "" && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+			if c.expectedError != "" && (err == nil || !strings.Contains(err.Error(), c.expectedError)) {
+				t.Errorf("expected an error containing %q, got %v", c.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestValidateFunctionArchiveUncompressedSize(t *testing.T) {
+	archive := testZipArchive(t, map[string]string{
+		"hello_world.py": "def handler(req, context):\n    pass\n",
+		"data.bin":       strings.Repeat("0", maxFunctionArchiveUncompressedSize),
+	})
+
+	err := validateFunctionArchive(archive, "hello_world.handler", "python3")
+	if err == nil || !strings.Contains(err.Error(), "uncompressed") {
+		t.Errorf("expected an uncompressed size error, got %v", err)
+	}
+}
+
+func TestCustomizeDiffEdgeFunctionArchive(t *testing.T) {
+	r := resourceLimelightEdgeFunction()
+	dir, err := ioutil.TempDir("", "limelight-archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	archive := filepath.Join(dir, "function.zip")
+	zipFile := testZipArchive(t, map[string]string{"hello_world.py": "def handler(req, context):\n    pass\n"})
+	if err := ioutil.WriteFile(archive, zipFile, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name          string
+		archive       string
+		handler       string
+		expectedError string
+	}{
+		{"valid", archive, "hello_world.handler", ""},
+		{"invalid handler", archive, "main.handler", "handler module main not found"},
+		{"archive not created yet", filepath.Join(dir, "missing.zip"), "main.handler", ""},
+		{"unknown archive", testUnknownValue, "main.handler", ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			config := map[string]interface{}{
+				"shortname":        "example",
+				"name":             "hello",
+				"function_archive": c.archive,
+				"function_sha256":  testSha256(zipFile),
+				"handler":          c.handler,
+				"runtime":          "python3",
+			}
+
+			_, err := r.Diff(nil, terraform.NewResourceConfigRaw(config), newFakeMeta())
+			if c.expectedError == "" && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+			if c.expectedError != "" && (err == nil || !strings.Contains(err.Error(), c.expectedError)) {
+				t.Errorf("expected an error containing %q, got %v", c.expectedError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package limelight
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	headerSecurityPrincipal = "X-LLNW-Security-Principal"
+	headerSecurityTimestamp = "X-LLNW-Security-Timestamp"
+	headerSecurityToken     = "X-LLNW-Security-Token"
+)
+
+// signRequest sets the Limelight HMAC authentication headers on req, using the same
+// scheme as the llnw-sdk-go clients so that requests they built can be re-signed.
+func signRequest(req *http.Request, username string, apiKey string, body []byte) {
+	u := *req.URL
+	u.RawQuery = ""
+	u.Fragment = ""
+
+	timestamp := strconv.FormatInt(time.Now().UnixNano()/1000000, 10)
+	data := req.Method + u.String() + req.URL.RawQuery + timestamp + string(body)
+
+	decodedAPIKey, _ := hex.DecodeString(apiKey)
+
+	tokenHmac := hmac.New(sha256.New, decodedAPIKey)
+	tokenHmac.Write([]byte(data))
+
+	req.Header.Set(headerSecurityPrincipal, username)
+	req.Header.Set(headerSecurityTimestamp, timestamp)
+	req.Header.Set(headerSecurityToken, hex.EncodeToString(tokenHmac.Sum(nil)))
+}
@@ -0,0 +1,16 @@
+package limelight
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+// configureFromEnvironment configures the provider for the subcommands of the provider
+// binary, from the same environment variables as the provider's arguments.
+func configureFromEnvironment() (*schema.Provider, error) {
+	provider := Provider().(*schema.Provider)
+	if err := provider.Configure(terraform.NewResourceConfigRaw(map[string]interface{}{})); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
@@ -0,0 +1,331 @@
+package limelight
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/llnw/llnw-sdk-go/configuration"
+)
+
+// configurationAPIRequestInterval is how far apart the Configuration API requests are
+// sent, to stay under the API's rate limit.
+const configurationAPIRequestInterval = 1200 * time.Millisecond
+
+const defaultConfigurationAPIBaseURL = "https://apis.llnw.com/config-api/v1"
+
+// ipAllowListURL is where the IP ranges of the CDN are listed, outside of the
+// Configuration API's base URL.
+const ipAllowListURL = "https://control.llnw.com/aportal/api/ipam/getIpAllowList.do"
+
+// configurationClient is a client for the Limelight Configuration API, implementing the
+// calls of the SDK client the provider uses. All of its requests share one rate limiter.
+type configurationClient struct {
+	*apiClient
+}
+
+func newConfigurationClient(apiUser string, apiKey string, baseURL string) *configurationClient {
+	if baseURL == "" {
+		baseURL = defaultConfigurationAPIBaseURL
+	}
+
+	client := newAPIClient(apiUser, apiKey, baseURL)
+	client.limiter = &rateLimiter{interval: configurationAPIRequestInterval}
+	return &configurationClient{client}
+}
+
+func (c *configurationClient) GetConfigurationOptions(shortname string, profileName string) ([]configuration.ConfigOption, *http.Response, error) {
+	body, response, err := c.get(fmt.Sprintf("%s/configoption/shortname/%s/svcProf/%s", c.BaseUrl, shortname, profileName))
+	if err != nil {
+		return nil, response, err
+	}
+
+	options := &configuration.ConfigOptionsResponse{}
+	if err := json.Unmarshal(body, options); err != nil {
+		return nil, response, fmt.Errorf("error decoding configuration options: %s", err)
+	}
+	return options.Results, response, nil
+}
+
+func (c *configurationClient) GetIPAllowList() (*configuration.IPAllowList, *http.Response, error) {
+	body, response, err := c.get(ipAllowListURL)
+	if err != nil {
+		return nil, response, err
+	}
+
+	ipAllowList := &configuration.IPAllowList{}
+	if err := json.Unmarshal(body, ipAllowList); err != nil {
+		return nil, response, fmt.Errorf("error decoding IP allow list: %s", err)
+	}
+	return ipAllowList, response, nil
+}
+
+func (c *configurationClient) GetRealtimeStreamingSlot(slotID string, shortname string) (*configuration.RealtimeStreamingSlot, *http.Response, error) {
+	body, response, err := c.get(fmt.Sprintf("%s/webrtc/shortname/%s/slots/%s", c.BaseUrl, shortname, slotID))
+	if err != nil {
+		return nil, response, err
+	}
+
+	slot := &configuration.RealtimeStreamingSlot{}
+	if err := json.Unmarshal(body, slot); err != nil {
+		return nil, response, fmt.Errorf("error decoding realtime streaming slot: %s", err)
+	}
+	return slot, response, nil
+}
+
+func (c *configurationClient) CreateRealtimeStreamingSlot(shortname string, slot *configuration.RealtimeStreamingSlot) (*configuration.RealtimeStreamingSlot, *http.Response, error) {
+	body, response, err := c.request(http.MethodPost, fmt.Sprintf("%s/webrtc/shortname/%s/slots", c.BaseUrl, shortname), slot, c.readTimeout)
+	if err != nil {
+		return nil, response, err
+	}
+
+	responseSlot := &configuration.RealtimeStreamingSlot{}
+	if err := json.Unmarshal(body, responseSlot); err != nil {
+		return nil, response, fmt.Errorf("error decoding realtime streaming slot: %s", err)
+	}
+	return responseSlot, response, nil
+}
+
+func (c *configurationClient) DeleteRealtimeStreamingSlot(slotID string, shortname string) (*http.Response, error) {
+	_, response, err := c.request(http.MethodDelete, fmt.Sprintf("%s/webrtc/shortname/%s/slots/%s", c.BaseUrl, shortname, slotID), nil, c.readTimeout)
+	return response, err
+}
+
+// serviceInstance is a Configuration API service instance whose body is kept as decoded
+// JSON, so that fields the SDK doesn't model are preserved.
+type serviceInstance struct {
+	UUID      string                  `json:"uuid"`
+	IsLatest  bool                    `json:"isLatest"`
+	IsEnabled bool                    `json:"isEnabled"`
+	Revision  configuration.Revision  `json:"revision"`
+	Accounts  []configuration.Account `json:"accounts"`
+	Shortname string                  `json:"shortname"`
+	Body      map[string]interface{}  `json:"body"`
+}
+
+type serviceInstanceRequest struct {
+	UUID     string                  `json:"uuid,omitempty"`
+	Body     map[string]interface{}  `json:"body"`
+	Accounts []configuration.Account `json:"accounts"`
+}
+
+type serviceInstanceList struct {
+	Results []serviceInstance `json:"results"`
+}
+
+// decodeBody decodes the service instance body into v, e.g. a DeliveryServiceInstanceBody.
+func (s *serviceInstance) decodeBody(v interface{}) error {
+	encoded, err := json.Marshal(s.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, v)
+}
+
+func getServiceInstance(c *configurationClient, serviceKey string, uuid string) (*serviceInstance, *http.Response, error) {
+	body, response, err := c.get(fmt.Sprintf("%s/svcinst/%s/%s", c.BaseUrl, serviceKey, uuid))
+
+	if err != nil {
+		return nil, response, err
+	}
+
+	return decodeServiceInstance(body, response)
+}
+
+func createServiceInstance(c *configurationClient, serviceKey string, body map[string]interface{}, shortname string, timeout time.Duration) (*serviceInstance, *http.Response, error) {
+	request := &serviceInstanceRequest{
+		Body:     body,
+		Accounts: []configuration.Account{{Shortname: shortname}},
+	}
+
+	respBody, response, err := c.request(http.MethodPost, fmt.Sprintf("%s/svcinst/%s", c.BaseUrl, serviceKey), request, timeout)
+
+	if err != nil {
+		return nil, response, err
+	}
+
+	return decodeServiceInstance(respBody, response)
+}
+
+func updateServiceInstance(c *configurationClient, serviceKey string, uuid string, body map[string]interface{}, shortname string, timeout time.Duration) (*serviceInstance, *http.Response, error) {
+	request := &serviceInstanceRequest{
+		UUID:     uuid,
+		Body:     body,
+		Accounts: []configuration.Account{{Shortname: shortname}},
+	}
+
+	respBody, response, err := c.request(http.MethodPut, fmt.Sprintf("%s/svcinst/%s/%s", c.BaseUrl, serviceKey, uuid), request, timeout)
+
+	if err != nil {
+		return nil, response, err
+	}
+
+	return decodeServiceInstance(respBody, response)
+}
+
+func deleteServiceInstance(c *configurationClient, serviceKey string, uuid string, timeout time.Duration) (*http.Response, error) {
+	_, response, err := c.request(http.MethodDelete, fmt.Sprintf("%s/svcinst/%s/%s", c.BaseUrl, serviceKey, uuid), nil, timeout)
+
+	return response, err
+}
+
+// listServiceInstances lists the latest revisions of the service instances of an account.
+func listServiceInstances(c *configurationClient, serviceKey string, shortname string) ([]serviceInstance, *http.Response, error) {
+	body, response, err := c.get(fmt.Sprintf("%s/svcinst/%s/shortname/%s", c.BaseUrl, serviceKey, shortname))
+
+	if err != nil {
+		return nil, response, err
+	}
+
+	list := &serviceInstanceList{}
+	if err := json.Unmarshal(body, list); err != nil {
+		return nil, response, fmt.Errorf("error decoding service instances: %s", err)
+	}
+
+	return list.Results, response, nil
+}
+
+// getServiceInstanceRevisions lists the revisions of a service instance, oldest first.
+func getServiceInstanceRevisions(c *configurationClient, serviceKey string, uuid string) ([]serviceInstance, *http.Response, error) {
+	body, response, err := c.get(fmt.Sprintf("%s/svcinst/%s/%s/revisions", c.BaseUrl, serviceKey, uuid))
+
+	if err != nil {
+		return nil, response, err
+	}
+
+	var revisions []serviceInstance
+	if err := json.Unmarshal(body, &revisions); err != nil {
+		return nil, response, fmt.Errorf("error decoding service instance revisions: %s", err)
+	}
+
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[i].Revision.VersionNumber < revisions[j].Revision.VersionNumber
+	})
+
+	return revisions, response, nil
+}
+
+func decodeServiceInstance(body []byte, response *http.Response) (*serviceInstance, *http.Response, error) {
+	instance := &serviceInstance{}
+	if err := json.Unmarshal(body, instance); err != nil {
+		return nil, response, fmt.Errorf("error decoding service instance: %s", err)
+	}
+
+	return instance, response, nil
+}
+
+// setServiceInstanceRevision sets the attributes describing the current revision of a
+// service instance, shared by the resources managing service instances.
+func setServiceInstanceRevision(d *schema.ResourceData, instance *serviceInstance) error {
+	effectiveBodyJSON, err := json.Marshal(instance.Body)
+	if err != nil {
+		return fmt.Errorf("error encoding service instance body: %s", err)
+	}
+
+	d.Set("version_number", instance.Revision.VersionNumber)
+	d.Set("effective_body_json", string(effectiveBodyJSON))
+
+	return nil
+}
+
+// toJSONObject converts a struct such as a DeliveryServiceInstanceBody to a decoded JSON
+// object.
+func toJSONObject(v interface{}) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	object := map[string]interface{}{}
+	if err := json.Unmarshal(encoded, &object); err != nil {
+		return nil, err
+	}
+
+	return object, nil
+}
+
+// parseJSONObject parses a JSON object argument such as body_json.
+func parseJSONObject(s string, name string) (map[string]interface{}, error) {
+	var object map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &object); err != nil || object == nil {
+		return nil, fmt.Errorf("%s must be a JSON object", name)
+	}
+
+	return object, nil
+}
+
+// mergeBodyJSON converts a service instance body to a JSON object and deep-merges the
+// body_json argument into it.
+func mergeBodyJSON(body interface{}, bodyJSON string) (map[string]interface{}, error) {
+	object, err := toJSONObject(body)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding service instance body: %s", err)
+	}
+
+	if bodyJSON == "" {
+		return object, nil
+	}
+
+	patch, err := parseJSONObject(bodyJSON, "body_json")
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeJSONObjects(object, patch), nil
+}
+
+// mergeJSONObjects deep-merges patch into target with JSON Merge Patch (RFC 7386)
+// semantics: objects are merged recursively, a null removes the field and any other
+// value replaces it.
+func mergeJSONObjects(target map[string]interface{}, patch map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(target)+len(patch))
+	for k, v := range target {
+		merged[k] = v
+	}
+
+	for k, patchValue := range patch {
+		if patchValue == nil {
+			delete(merged, k)
+			continue
+		}
+
+		patchObject, patchIsObject := patchValue.(map[string]interface{})
+		targetObject, targetIsObject := merged[k].(map[string]interface{})
+		if patchIsObject && targetIsObject {
+			merged[k] = mergeJSONObjects(targetObject, patchObject)
+		} else if patchIsObject {
+			merged[k] = mergeJSONObjects(map[string]interface{}{}, patchObject)
+		} else {
+			merged[k] = patchValue
+		}
+	}
+
+	return merged
+}
+
+// projectJSONObject returns the fields of object that are also present in mask,
+// recursing into nested objects. It is used to compare a user supplied body with the
+// full body stored by the API, which includes fields the user didn't set.
+func projectJSONObject(object map[string]interface{}, mask map[string]interface{}) map[string]interface{} {
+	projected := make(map[string]interface{}, len(mask))
+
+	for k, maskValue := range mask {
+		value, ok := object[k]
+		if !ok {
+			continue
+		}
+
+		maskObject, maskIsObject := maskValue.(map[string]interface{})
+		valueObject, valueIsObject := value.(map[string]interface{})
+		if maskIsObject && valueIsObject {
+			projected[k] = projectJSONObject(valueObject, maskObject)
+		} else {
+			projected[k] = value
+		}
+	}
+
+	return projected
+}
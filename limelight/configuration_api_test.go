@@ -0,0 +1,52 @@
+package limelight
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/llnw/llnw-sdk-go/configuration"
+)
+
+func TestMergeBodyJSON(t *testing.T) {
+	body := &configuration.DeliveryServiceInstanceBody{
+		ServiceProfileName: "LLNW-Generic",
+		PublishedHostname:  "www.example.com",
+		SourceHostname:     "origin.example.com",
+		ServiceKey:         configuration.ServiceKey{Name: "delivery"},
+	}
+
+	cases := []struct {
+		name     string
+		bodyJSON string
+		expected string
+	}{
+		{
+			name:     "no body_json",
+			bodyJSON: "",
+			expected: `{"protocolSets":null,"publishedHostname":"www.example.com","publishedUrlPath":"","serviceKey":{"name":"delivery"},"serviceProfileName":"LLNW-Generic","sourceHostname":"origin.example.com","sourceUrlPath":""}`,
+		},
+		{
+			name:     "added, replaced, nested and removed fields",
+			bodyJSON: `{"sourceHostname": "other.example.com", "serviceKey": {"version": 2}, "protocolSets": null, "publishedUrlPath": null, "sourceUrlPath": null, "tags": ["a"]}`,
+			expected: `{"publishedHostname":"www.example.com","serviceKey":{"name":"delivery","version":2},"serviceProfileName":"LLNW-Generic","sourceHostname":"other.example.com","tags":["a"]}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			merged, err := mergeBodyJSON(body, tc.bodyJSON)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			encoded, _ := json.Marshal(merged)
+			if string(encoded) != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, encoded)
+			}
+		})
+	}
+
+	if _, err := mergeBodyJSON(body, `["not", "an", "object"]`); err == nil {
+		t.Errorf("expected an error for a body_json that isn't an object")
+	}
+}
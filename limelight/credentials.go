@@ -0,0 +1,212 @@
+package limelight
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/llnw/llnw-sdk-go"
+)
+
+// credentialProcessTimeout bounds how long a credential_process command may run.
+const credentialProcessTimeout = 1 * time.Minute
+
+// credentialProcessExpiryWindow refreshes credentials slightly before they expire so
+// that a request signed with them doesn't expire in flight.
+const credentialProcessExpiryWindow = 30 * time.Second
+
+type credentialProcessOutput struct {
+	Username   string `json:"username"`
+	APIKey     string `json:"api_key"`
+	Expiration string `json:"expiration"`
+}
+
+// credentialProcess runs an external command to obtain API credentials, caching them
+// until they expire. The Auth of the clients is only set when they are registered, as
+// the clients read it without synchronization while resources are applied in parallel:
+// credentialProcessTransport signs every request with the current credentials instead.
+type credentialProcess struct {
+	command    string
+	lock       sync.Mutex
+	username   string
+	apiKey     string
+	expiration time.Time
+}
+
+func newCredentialProcess(command string) *credentialProcess {
+	return &credentialProcess{command: command}
+}
+
+// register sets the Auth of a client to the current credentials, before the client is
+// used.
+func (p *credentialProcess) register(auth *llnw.Auth) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	auth.APIUser = p.username
+	auth.APIKey = p.apiKey
+}
+
+// credentials returns the cached credentials, re-running the command when they have
+// expired.
+func (p *credentialProcess) credentials() (string, string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.apiKey != "" && !p.expiredLocked() {
+		return p.username, p.apiKey, nil
+	}
+
+	return p.refreshLocked()
+}
+
+// refresh re-runs the command after the API rejected rejectedAPIKey, unless the
+// credentials have been refreshed since, e.g. by a concurrent request.
+func (p *credentialProcess) refresh(rejectedAPIKey string) (string, string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.apiKey != "" && p.apiKey != rejectedAPIKey && !p.expiredLocked() {
+		return p.username, p.apiKey, nil
+	}
+
+	return p.refreshLocked()
+}
+
+func (p *credentialProcess) refreshLocked() (string, string, error) {
+	output, err := p.run()
+	if err != nil {
+		return "", "", err
+	}
+
+	p.username = output.Username
+	p.apiKey = output.APIKey
+	p.expiration = time.Time{}
+	if output.Expiration != "" {
+		p.expiration, err = time.Parse(time.RFC3339, output.Expiration)
+		if err != nil {
+			return "", "", fmt.Errorf("credential_process returned an invalid expiration %q (expected RFC 3339): %s", output.Expiration, err)
+		}
+	}
+
+	return p.username, p.apiKey, nil
+}
+
+func (p *credentialProcess) expired() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.expiredLocked()
+}
+
+func (p *credentialProcess) expiredLocked() bool {
+	if p.expiration.IsZero() {
+		return false
+	}
+	return time.Now().Add(credentialProcessExpiryWindow).After(p.expiration)
+}
+
+func (p *credentialProcess) run() (*credentialProcessOutput, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), credentialProcessTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd.exe", "/C", p.command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", p.command)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	log.Printf("[DEBUG] Running credential_process")
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("error running credential_process: %s: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	output := &credentialProcessOutput{}
+	if err := json.Unmarshal(stdout.Bytes(), output); err != nil {
+		return nil, fmt.Errorf("error parsing credential_process output as JSON: %s", err)
+	}
+
+	if output.Username == "" || output.APIKey == "" {
+		return nil, fmt.Errorf("credential_process output must contain both username and api_key")
+	}
+
+	return output, nil
+}
+
+// credentialProcessTransport signs requests with the current credentials, and refreshes
+// the credentials and retries once when the API rejects them as unauthenticated.
+type credentialProcessTransport struct {
+	process   *credentialProcess
+	transport http.RoundTripper
+}
+
+func (t *credentialProcessTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get(headerSecurityPrincipal) == "" {
+		return t.transport.RoundTrip(req)
+	}
+
+	username, apiKey, err := t.process.credentials()
+	if err != nil {
+		return nil, err
+	}
+	signed, err := resign(req, username, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.transport.RoundTrip(signed)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	log.Printf("[DEBUG] %s %s was rejected with status %d, refreshing credential_process credentials", req.Method, redactURL(req.URL), resp.StatusCode)
+	username, apiKey, err = t.process.refresh(apiKey)
+	if err != nil {
+		log.Printf("[WARN] Failed to refresh credential_process credentials: %s", err)
+		return resp, nil
+	}
+	retry, err := resign(req, username, apiKey)
+	if err != nil {
+		log.Printf("[WARN] Failed to sign the request with refreshed credential_process credentials: %s", err)
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	return t.transport.RoundTrip(retry)
+}
+
+// resign returns a copy of req signed with the given credentials.
+func resign(req *http.Request, username string, apiKey string) (*http.Request, error) {
+	signed := req.Clone(req.Context())
+
+	var body []byte
+	if req.Body != nil && req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		body, err = ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		signed.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	signRequest(signed, username, apiKey, body)
+
+	return signed, nil
+}
@@ -0,0 +1,167 @@
+package limelight
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/llnw/llnw-sdk-go"
+)
+
+func TestCredentialProcess_credentials(t *testing.T) {
+	expiration := time.Now().Add(1 * time.Hour).UTC().Format(time.RFC3339)
+	p := newCredentialProcess(`echo '{"username": "user", "api_key": "abcd", "expiration": "` + expiration + `"}'`)
+
+	username, apiKey, err := p.credentials()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if username != "user" || apiKey != "abcd" {
+		t.Fatalf("unexpected credentials %q/%q", username, apiKey)
+	}
+
+	auth := &llnw.Auth{}
+	p.register(auth)
+	if auth.APIUser != "user" || auth.APIKey != "abcd" {
+		t.Fatalf("registered auth was not set, got %q/%q", auth.APIUser, auth.APIKey)
+	}
+	if p.expired() {
+		t.Fatalf("credentials should not have expired")
+	}
+}
+
+func TestCredentialProcess_expired(t *testing.T) {
+	expiration := time.Now().Add(-1 * time.Minute).UTC().Format(time.RFC3339)
+	p := newCredentialProcess(`echo '{"username": "user", "api_key": "abcd", "expiration": "` + expiration + `"}'`)
+
+	if _, _, err := p.credentials(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !p.expired() {
+		t.Fatalf("credentials should have expired")
+	}
+}
+
+func TestCredentialProcess_invalidOutput(t *testing.T) {
+	for _, command := range []string{
+		`echo 'not json'`,
+		`echo '{"username": "user"}'`,
+		`exit 1`,
+	} {
+		if _, _, err := newCredentialProcess(command).credentials(); err == nil {
+			t.Fatalf("expected an error for command %q", command)
+		}
+	}
+}
+
+// testSignature returns the token of a request to a test server signed with apiKey.
+func testSignature(r *http.Request, apiKey string) string {
+	key, _ := hex.DecodeString(apiKey)
+	token := hmac.New(sha256.New, key)
+	token.Write([]byte(r.Method + "http://" + r.Host + r.URL.Path + r.URL.RawQuery + r.Header.Get(headerSecurityTimestamp)))
+	return hex.EncodeToString(token.Sum(nil))
+}
+
+func TestCredentialProcessTransport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credential-process")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	keyFile := filepath.Join(dir, "key")
+	if err := ioutil.WriteFile(keyFile, []byte("00aa"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	expiration := time.Now().Add(1 * time.Hour).UTC().Format(time.RFC3339)
+	p := newCredentialProcess(fmt.Sprintf(`echo '{"username": "user", "api_key": "'$(cat %s)'", "expiration": "%s"}'`, keyFile, expiration))
+	if _, _, err := p.credentials(); err != nil {
+		t.Fatal(err)
+	}
+	auth := &llnw.Auth{}
+	p.register(auth)
+
+	var lock sync.Mutex
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		requests++
+		lock.Unlock()
+
+		switch {
+		case r.URL.Path == "/forbidden":
+			w.WriteHeader(http.StatusForbidden)
+		case r.Header.Get(headerSecurityToken) != testSignature(r, "00bb"):
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &credentialProcessTransport{process: p, transport: http.DefaultTransport}}
+	get := func(path string) (int, error) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		if err != nil {
+			return 0, err
+		}
+		signRequest(req, auth.APIUser, auth.APIKey, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		resp.Body.Close()
+		return resp.StatusCode, nil
+	}
+
+	// The API rotated the key: the rejected requests are retried with the new one.
+	if err := ioutil.WriteFile(keyFile, []byte("00bb"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	statuses := make(chan int, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status, err := get("/ok")
+			if err != nil {
+				t.Error(err)
+			}
+			statuses <- status
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+	for status := range statuses {
+		if status != http.StatusOK {
+			t.Errorf("expected the retried request to succeed, got status %d", status)
+		}
+	}
+	if _, apiKey, _ := p.credentials(); apiKey != "00bb" {
+		t.Errorf("expected the credentials to be refreshed, got %q", apiKey)
+	}
+
+	// A 403 isn't an authentication failure, so it isn't retried.
+	if err := ioutil.WriteFile(keyFile, []byte("00cc"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	requests = 0
+	status, err := get("/forbidden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != http.StatusForbidden || requests != 1 {
+		t.Errorf("expected a single request rejected with status 403, got status %d after %d requests", status, requests)
+	}
+	if _, apiKey, _ := p.credentials(); apiKey != "00bb" {
+		t.Errorf("expected the credentials not to be refreshed, got %q", apiKey)
+	}
+}
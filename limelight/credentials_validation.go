@@ -0,0 +1,115 @@
+package limelight
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxClockSkew is how far the clock may be off the API's before rejected requests are
+// put down to it: requests are signed with the current time, and the API only accepts
+// recent signatures.
+const maxClockSkew = 5 * time.Minute
+
+// credentialsCheck is a lightweight authenticated request to one of the APIs, made when
+// the provider is configured.
+type credentialsCheck struct {
+	api         string
+	permissions string
+	client      *apiClient
+	url         string
+}
+
+// credentialsChecks returns the requests that validate the credentials with each API,
+// which list the delivery configurations and EdgeFunctions of the account.
+func credentialsChecks(meta *Meta, shortname string) []credentialsCheck {
+	return []credentialsCheck{
+		{
+			api:         "Configuration",
+			permissions: "Manage permissions for Caching & Delivery as well as Config API Access",
+			client:      meta.ConfigurationClient.apiClient,
+			url:         fmt.Sprintf("%s/svcinst/%s/shortname/%s", meta.ConfigurationClient.BaseUrl, deliveryServiceKey, shortname),
+		},
+		{
+			api:         "EdgeFunctions",
+			permissions: "Manage permissions for EdgeFunctions",
+			client:      meta.EdgeFunctionsClient.apiClient,
+			url:         fmt.Sprintf("%s/%s/functions", meta.EdgeFunctionsClient.BaseUrl, shortname),
+		},
+	}
+}
+
+// validateCredentials makes each check, and returns an error describing why the
+// credentials were rejected by any of the APIs.
+func validateCredentials(checks []credentialsCheck) error {
+	var problems []string
+	for _, check := range checks {
+		if err := validateAPIKey(check.client); err != nil {
+			return err
+		}
+
+		log.Printf("[INFO] Validating credentials with the %s API", check.api)
+		_, resp, err := check.client.get(check.url)
+		if err != nil {
+			problems = append(problems, diagnoseCredentialsError(check, resp, err))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("error validating credentials (set skip_credentials_validation to skip this):\n  %s", strings.Join(problems, "\n  "))
+}
+
+// validateAPIKey checks the format of the API key of client, which can be done without
+// a request.
+func validateAPIKey(client *apiClient) error {
+	if _, err := hex.DecodeString(client.Auth.APIKey); err != nil {
+		return fmt.Errorf("invalid api_key for username %q: it must be the hexadecimal shared key shown in the Control portal", client.Auth.APIUser)
+	}
+	return nil
+}
+
+// diagnoseCredentialsError describes why a credentials check failed: a wrong username or
+// API key, the clock being off, or missing permissions for the API.
+func diagnoseCredentialsError(check credentialsCheck, resp *http.Response, err error) string {
+	if resp == nil {
+		return fmt.Sprintf("%s API: %s", check.api, err)
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		if date, dateErr := http.ParseTime(resp.Header.Get("Date")); dateErr == nil {
+			if skew := time.Since(date); skew > maxClockSkew || skew < -maxClockSkew {
+				return fmt.Sprintf("%s API: the clock of this machine is %s off the API's, so the signatures of the requests are rejected. Synchronize the clock, e.g. with NTP",
+					check.api, skew.Round(time.Second))
+			}
+		}
+	}
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	message := strings.ToLower(string(body))
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		switch {
+		case strings.Contains(message, "timestamp") || strings.Contains(message, "expired") || strings.Contains(message, "clock"):
+			return fmt.Sprintf("%s API: the request signature was rejected as expired, the clock of this machine may be off. Synchronize the clock, e.g. with NTP (%s)",
+				check.api, body)
+		case strings.Contains(message, "token") || strings.Contains(message, "signature") || strings.Contains(message, "key"):
+			return fmt.Sprintf("%s API: the api_key of username %q was rejected. Check api_key (%s)", check.api, check.client.Auth.APIUser, body)
+		case strings.Contains(message, "principal") || strings.Contains(message, "user"):
+			return fmt.Sprintf("%s API: username %q was rejected. Check username (%s)", check.api, check.client.Auth.APIUser, body)
+		}
+		return fmt.Sprintf("%s API: username %q or its api_key was rejected (%s)", check.api, check.client.Auth.APIUser, body)
+	case http.StatusForbidden:
+		return fmt.Sprintf("%s API: username %q isn't allowed to use the API, it needs %s (%s)", check.api, check.client.Auth.APIUser, check.permissions, body)
+	case http.StatusNotFound:
+		return fmt.Sprintf("%s API: the account of default_shortname wasn't found (%s)", check.api, body)
+	}
+
+	return fmt.Sprintf("%s API: %s", check.api, err)
+}
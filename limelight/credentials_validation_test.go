@@ -0,0 +1,81 @@
+package limelight
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateCredentials(t *testing.T) {
+	cases := []struct {
+		name          string
+		apiKey        string
+		status        int
+		date          time.Time
+		body          string
+		expectedError string
+	}{
+		{"valid", "00ff", http.StatusOK, time.Now(), "[]", ""},
+		{"invalid key", "not-hex", http.StatusOK, time.Now(), "[]", `invalid api_key for username "user"`},
+		{"wrong username", "00ff", http.StatusUnauthorized, time.Now(), "Unknown security principal", `Example API: username "user" was rejected`},
+		{"wrong key", "00ff", http.StatusUnauthorized, time.Now(), "Invalid security token", `Example API: the api_key of username "user" was rejected`},
+		{"expired signature", "00ff", http.StatusUnauthorized, time.Now(), "Timestamp expired", "the clock of this machine may be off"},
+		{"clock skew", "00ff", http.StatusUnauthorized, time.Now().Add(-time.Hour), "", "the clock of this machine is 1h0m"},
+		{"unknown rejection", "00ff", http.StatusUnauthorized, time.Now(), "", `username "user" or its api_key was rejected`},
+		{"permissions", "00ff", http.StatusForbidden, time.Now(), "Forbidden", `username "user" isn't allowed to use the API, it needs Manage permissions for Examples`},
+		{"unknown shortname", "00ff", http.StatusNotFound, time.Now(), "", "the account of default_shortname wasn't found"},
+		{"other error", "00ff", http.StatusInternalServerError, time.Now(), "oops", "Example API: non-2XX status code from API, got status 500: oops"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var principal string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				principal = r.Header.Get(headerSecurityPrincipal)
+				w.Header().Set("Date", c.date.UTC().Format(http.TimeFormat))
+				w.WriteHeader(c.status)
+				w.Write([]byte(c.body))
+			}))
+			defer server.Close()
+
+			err := validateCredentials([]credentialsCheck{
+				{
+					api:         "Example",
+					permissions: "Manage permissions for Examples",
+					client:      newAPIClient("user", c.apiKey, server.URL),
+					url:         server.URL + "/example/functions",
+				},
+			})
+
+			if c.expectedError == "" && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+			if c.expectedError != "" && (err == nil || !strings.Contains(err.Error(), c.expectedError)) {
+				t.Errorf("expected an error containing %q, got %v", c.expectedError, err)
+			}
+			if c.apiKey == "00ff" && principal != "user" {
+				t.Errorf("expected a request signed by user, got %q", principal)
+			}
+		})
+	}
+}
+
+func TestCredentialsChecks(t *testing.T) {
+	meta := &Meta{
+		ConfigurationClient: newConfigurationClient("user", "00", "https://config.example.com"),
+		EdgeFunctionsClient: newEdgeFunctionsClient("user", "00", "https://edgefunctions.example.com"),
+	}
+
+	checks := credentialsChecks(meta, "example")
+	var urls []string
+	for _, check := range checks {
+		urls = append(urls, check.url)
+	}
+
+	expected := "https://config.example.com/svcinst/delivery/shortname/example,https://edgefunctions.example.com/example/functions"
+	if strings.Join(urls, ",") != expected {
+		t.Errorf("expected %s, got %v", expected, urls)
+	}
+}
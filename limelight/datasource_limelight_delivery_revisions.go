@@ -0,0 +1,105 @@
+package limelight
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+func dataSourceLimelightDeliveryRevisions() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceLimelightDeliveryRevisionsRead,
+		Schema: map[string]*schema.Schema{
+			"delivery_id": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"revisions": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"version_number": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"created_by": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"created_date": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"is_latest": &schema.Schema{
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"body_json": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"latest_version_number": &schema.Schema{
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceLimelightDeliveryRevisionsRead(d *schema.ResourceData, m interface{}) error {
+	c := getMeta(m).Deliveries
+	uuid := d.Get("delivery_id").(string)
+
+	log.Printf("[INFO] Fetching revisions of delivery configuration: %s", uuid)
+	revisions, _, err := c.GetDeliveryRevisions(uuid)
+	if err != nil {
+		return fmt.Errorf("error reading delivery configuration revisions: %s", err)
+	}
+
+	flattenedRevisions, err := flattenServiceInstanceRevisions(revisions)
+	if err != nil {
+		return err
+	}
+
+	latestVersionNumber := 0
+	for _, revision := range revisions {
+		if revision.Revision.VersionNumber > latestVersionNumber {
+			latestVersionNumber = revision.Revision.VersionNumber
+		}
+	}
+
+	d.SetId(uuid)
+	d.Set("revisions", flattenedRevisions)
+	d.Set("latest_version_number", latestVersionNumber)
+	return nil
+}
+
+func flattenServiceInstanceRevisions(revisions []serviceInstance) ([]map[string]interface{}, error) {
+	flattenedRevisions := make([]map[string]interface{}, len(revisions), len(revisions))
+
+	for i, v := range revisions {
+		bodyJSON, err := json.Marshal(v.Body)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding service instance body: %s", err)
+		}
+
+		m := make(map[string]interface{})
+		m["version_number"] = v.Revision.VersionNumber
+		m["created_by"] = v.Revision.CreatedBy
+		m["created_date"] = time.Unix(0, v.Revision.CreatedDate*int64(time.Millisecond)).UTC().Format(time.RFC3339)
+		m["is_latest"] = v.IsLatest
+		m["body_json"] = string(bodyJSON)
+		flattenedRevisions[i] = m
+	}
+
+	return flattenedRevisions, nil
+}
@@ -0,0 +1,83 @@
+package limelight
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/llnw/llnw-sdk-go/configuration"
+)
+
+func TestAccDataSourceLimelightDeliveryRevisions_basic(t *testing.T) {
+	testResourceName := "data.limelight_delivery_revisions.revisions"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceLimelightDeliveryRevisionsBasicTemplate(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(testResourceName, "id", "limelight_delivery.test_delivery", "id"),
+					resource.TestCheckResourceAttrPair(testResourceName, "latest_version_number", "limelight_delivery.test_delivery", "version_number"),
+					resource.TestCheckResourceAttrSet(testResourceName, "revisions.0.created_by"),
+					resource.TestCheckResourceAttrSet(testResourceName, "revisions.0.body_json"),
+				),
+			},
+		},
+	})
+}
+
+func TestFlattenServiceInstanceRevisions(t *testing.T) {
+	revisions := []serviceInstance{
+		{
+			IsLatest: true,
+			Revision: configuration.Revision{
+				CreatedBy:     "user@example.com",
+				CreatedDate:   1577836800123,
+				VersionNumber: 2,
+			},
+			Body: map[string]interface{}{
+				"publishedHostname": "www.example.com",
+			},
+		},
+	}
+
+	flattened, err := flattenServiceInstanceRevisions(revisions)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"version_number": 2,
+		"created_by":     "user@example.com",
+		"created_date":   "2020-01-01T00:00:00Z",
+		"is_latest":      true,
+		"body_json":      `{"publishedHostname":"www.example.com"}`,
+	}
+	for k, v := range expected {
+		if flattened[0][k] != v {
+			t.Errorf("%s: expected %v, got %v", k, v, flattened[0][k])
+		}
+	}
+}
+
+func testAccDataSourceLimelightDeliveryRevisionsBasicTemplate() string {
+	return fmt.Sprintf(`
+resource "limelight_delivery" "test_delivery" {
+	shortname          = "%s"
+	published_hostname = "terraform-test-revisions.%s.s.llnwi.net"
+	published_path     = "/"
+	source_hostname    = "dummy-origin-revisions.llnw.net"
+	source_path        = "/"
+	
+	protocol_set {
+		published_protocol = "https"
+		source_protocol    = "https"
+	}
+}
+
+data "limelight_delivery_revisions" "revisions" {
+	delivery_id = limelight_delivery.test_delivery.id
+}`, getShortname(), getShortname())
+}
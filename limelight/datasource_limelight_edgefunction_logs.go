@@ -0,0 +1,145 @@
+package limelight
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/hashcode"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+const defaultEdgeFunctionLogsWindow = time.Hour
+
+var edgeFunctionLogLevels = []string{"DEBUG", "INFO", "WARN", "ERROR"}
+
+func dataSourceLimelightEdgeFunctionLogs() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceLimelightEdgeFunctionLogsRead,
+		Schema: map[string]*schema.Schema{
+			"shortname": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"function_name": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"alias": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"start_time": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+			},
+			"end_time": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+			},
+			"request_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"level": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(edgeFunctionLogLevels, false),
+			},
+			"max_entries": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      100,
+				ValidateFunc: validation.IntBetween(1, 10000),
+			},
+			"entries": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"timestamp": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"request_id": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"level": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"message": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceLimelightEdgeFunctionLogsRead(d *schema.ResourceData, m interface{}) error {
+	c := getEdgeFunctionsClient(m)
+
+	shortname, err := resolveShortname(d, m)
+	if err != nil {
+		return err
+	}
+	fnName := d.Get("function_name").(string)
+
+	query := edgeFunctionLogQuery{
+		Alias:     d.Get("alias").(string),
+		RequestID: d.Get("request_id").(string),
+		Level:     d.Get("level").(string),
+	}
+
+	// The times were validated by the schema.
+	query.End = time.Now()
+	if v, ok := d.GetOk("end_time"); ok {
+		query.End, _ = time.Parse(time.RFC3339, v.(string))
+	}
+	query.Start = query.End.Add(-defaultEdgeFunctionLogsWindow)
+	if v, ok := d.GetOk("start_time"); ok {
+		query.Start, _ = time.Parse(time.RFC3339, v.(string))
+	}
+	if query.Start.After(query.End) {
+		return fmt.Errorf("start_time must not be after end_time")
+	}
+
+	log.Printf("[INFO] Fetching logs of EdgeFunction %s from %s to %s", fnName, query.Start.Format(time.RFC3339), query.End.Format(time.RFC3339))
+	entries, err := collectLatestEdgeFunctionLogs(c, fnName, shortname, query, d.Get("max_entries").(int))
+	if err != nil {
+		return fmt.Errorf("error reading EdgeFunction logs: %s", err)
+	}
+
+	d.SetId(strconv.Itoa(hashcode.String(strings.Join([]string{
+		shortname, fnName, query.Alias, query.Start.Format(time.RFC3339), query.End.Format(time.RFC3339), query.RequestID, query.Level,
+	}, ":"))))
+	d.Set("shortname", shortname)
+	d.Set("entries", flattenEdgeFunctionLogEntries(entries))
+	return nil
+}
+
+func flattenEdgeFunctionLogEntries(entries []edgeFunctionLogEntry) []map[string]interface{} {
+	flattenedEntries := make([]map[string]interface{}, len(entries), len(entries))
+
+	for i, v := range entries {
+		m := make(map[string]interface{})
+		m["timestamp"] = v.time().Format(time.RFC3339Nano)
+		m["request_id"] = v.RequestID
+		m["level"] = v.Level
+		m["message"] = v.Message
+		flattenedEntries[i] = m
+	}
+
+	return flattenedEntries
+}
@@ -0,0 +1,143 @@
+package limelight
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccDataSourceLimelightEdgeFunctionLogs_basic(t *testing.T) {
+	testResourceName := "data.limelight_edgefunction_logs.logs"
+	fnName := "terraform_ef_test_logs"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		CheckDestroy: func(state *terraform.State) error {
+			return testAccLimelightEdgeFunctionCheckDestroy(state, fnName)
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceLimelightEdgeFunctionLogsBasicTemplate(fnName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet(testResourceName, "id"),
+					resource.TestCheckResourceAttr(testResourceName, "shortname", getShortname()),
+					resource.TestCheckResourceAttrSet(testResourceName, "entries.#"),
+				),
+			},
+		},
+	})
+}
+
+func TestCollectEdgeFunctionLogs(t *testing.T) {
+	pages := map[string]edgeFunctionLogPage{
+		"": {
+			Entries:   []edgeFunctionLogEntry{{Timestamp: 1, Message: "one"}, {Timestamp: 2, Message: "two"}},
+			NextToken: "page2",
+		},
+		"page2": {
+			Entries:   []edgeFunctionLogEntry{{Timestamp: 3, Message: "three"}, {Timestamp: 4, Message: "four"}},
+			NextToken: "page3",
+		},
+		"page3": {
+			Entries: []edgeFunctionLogEntry{{Timestamp: 5, Message: "five"}},
+		},
+	}
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI())
+		json.NewEncoder(w).Encode(pages[r.URL.Query().Get("nextToken")])
+	}))
+	defer server.Close()
+
+	c := newEdgeFunctionsClient("user", "00", server.URL)
+	query := edgeFunctionLogQuery{Level: "ERROR"}
+
+	entries, err := collectEdgeFunctionLogs(c, "fn", "example", query, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(entries) != 5 || entries[4].Message != "five" {
+		t.Errorf("expected all 5 entries, got %v", entries)
+	}
+	if requests[0] != "/example/functions/fn/logs?level=ERROR&limit=10" {
+		t.Errorf("unexpected first request %s", requests[0])
+	}
+
+	requests = nil
+	entries, err = collectEdgeFunctionLogs(c, "fn", "example", query, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(entries) != 3 || entries[2].Message != "three" {
+		t.Errorf("expected the first 3 entries, got %v", entries)
+	}
+	if len(requests) != 2 || requests[1] != "/example/functions/fn/logs?level=ERROR&limit=1&nextToken=page2" {
+		t.Errorf("unexpected requests %v", requests)
+	}
+}
+
+func TestCollectLatestEdgeFunctionLogs(t *testing.T) {
+	pages := map[string]edgeFunctionLogPage{
+		"": {
+			Entries:   []edgeFunctionLogEntry{{Timestamp: 1, Message: "one"}, {Timestamp: 2, Message: "two"}, {Timestamp: 3, Message: "three"}},
+			NextToken: "page2",
+		},
+		"page2": {
+			Entries:   []edgeFunctionLogEntry{{Timestamp: 4, Message: "four"}, {Timestamp: 5, Message: "five"}, {Timestamp: 6, Message: "six"}},
+			NextToken: "page3",
+		},
+		"page3": {
+			Entries: []edgeFunctionLogEntry{{Timestamp: 7, Message: "seven"}},
+		},
+	}
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI())
+		json.NewEncoder(w).Encode(pages[r.URL.Query().Get("nextToken")])
+	}))
+	defer server.Close()
+
+	c := newEdgeFunctionsClient("user", "00", server.URL)
+
+	entries, err := collectLatestEdgeFunctionLogs(c, "fn", "example", edgeFunctionLogQuery{}, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var messages []string
+	for _, entry := range entries {
+		messages = append(messages, entry.Message)
+	}
+	if strings.Join(messages, ",") != "five,six,seven" {
+		t.Errorf("expected the latest 3 entries, oldest first, got %v", messages)
+	}
+	if len(requests) != 3 || requests[0] != "/example/functions/fn/logs?limit=3" {
+		t.Errorf("unexpected requests %v", requests)
+	}
+}
+
+func testAccDataSourceLimelightEdgeFunctionLogsBasicTemplate(fnName string) string {
+	return fmt.Sprintf(`
+resource "limelight_edgefunction" "test_ef" {
+	shortname        = "%s"
+	name             = "%s"
+	function_archive = "testdata/edgefunc/py_function.zip"
+	handler          = "hello_world.handler"
+	runtime          = "python3"
+	function_sha256  = filesha256("testdata/edgefunc/py_function.zip")
+}
+
+data "limelight_edgefunction_logs" "logs" {
+	shortname     = limelight_edgefunction.test_ef.shortname
+	function_name = limelight_edgefunction.test_ef.name
+	level         = "ERROR"
+}`, getShortname(), fnName)
+}
@@ -0,0 +1,182 @@
+package limelight
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/hashcode"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+// edgeFunctionMetricsSchema returns the attributes of a set of EdgeFunction metrics.
+func edgeFunctionMetricsSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"invocations": &schema.Schema{
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"errors": &schema.Schema{
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"throttles": &schema.Schema{
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"error_rate": &schema.Schema{
+			Type:     schema.TypeFloat,
+			Computed: true,
+		},
+		"duration_p50": &schema.Schema{
+			Type:     schema.TypeFloat,
+			Computed: true,
+		},
+		"duration_p95": &schema.Schema{
+			Type:     schema.TypeFloat,
+			Computed: true,
+		},
+		"duration_p99": &schema.Schema{
+			Type:     schema.TypeFloat,
+			Computed: true,
+		},
+		"max_concurrency": &schema.Schema{
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+	}
+}
+
+func dataSourceLimelightEdgeFunctionMetrics() *schema.Resource {
+	datapointSchema := edgeFunctionMetricsSchema()
+	datapointSchema["timestamp"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+
+	s := map[string]*schema.Schema{
+		"shortname": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"function_name": &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			ValidateFunc: validation.NoZeroValues,
+		},
+		"alias": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"end_time": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.IsRFC3339Time,
+		},
+		"window": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "1h",
+			ValidateFunc: validatePositiveDuration,
+		},
+		"granularity": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "5m",
+			ValidateFunc: validatePositiveDuration,
+		},
+		"start_time": &schema.Schema{
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"datapoints": &schema.Schema{
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: datapointSchema,
+			},
+		},
+	}
+	// The summary of the whole window has the same attributes as each datapoint.
+	for k, v := range edgeFunctionMetricsSchema() {
+		s[k] = v
+	}
+
+	return &schema.Resource{
+		Read:   dataSourceLimelightEdgeFunctionMetricsRead,
+		Schema: s,
+	}
+}
+
+func dataSourceLimelightEdgeFunctionMetricsRead(d *schema.ResourceData, m interface{}) error {
+	c := getEdgeFunctionsClient(m)
+
+	shortname, err := resolveShortname(d, m)
+	if err != nil {
+		return err
+	}
+	fnName := d.Get("function_name").(string)
+
+	// The times and durations were validated by the schema.
+	window, _ := time.ParseDuration(d.Get("window").(string))
+	granularity, _ := time.ParseDuration(d.Get("granularity").(string))
+	if granularity > window {
+		return fmt.Errorf("granularity must not be longer than window")
+	}
+
+	query := edgeFunctionMetricsQuery{
+		Alias:       d.Get("alias").(string),
+		End:         time.Now().UTC(),
+		Granularity: granularity,
+	}
+	if v, ok := d.GetOk("end_time"); ok {
+		query.End, _ = time.Parse(time.RFC3339, v.(string))
+	}
+	query.Start = query.End.Add(-window)
+
+	log.Printf("[INFO] Fetching metrics of EdgeFunction %s from %s to %s", fnName, query.Start.Format(time.RFC3339), query.End.Format(time.RFC3339))
+	metrics, _, err := getEdgeFunctionMetrics(c, fnName, shortname, query)
+	if err != nil {
+		return fmt.Errorf("error reading EdgeFunction metrics: %s", err)
+	}
+
+	d.SetId(strconv.Itoa(hashcode.String(strings.Join([]string{
+		shortname, fnName, query.Alias, query.Start.Format(time.RFC3339), query.End.Format(time.RFC3339), query.Granularity.String(),
+	}, ":"))))
+	d.Set("shortname", shortname)
+	d.Set("start_time", query.Start.Format(time.RFC3339))
+	for k, v := range flattenEdgeFunctionMetricsDatapoint(metrics.Summary) {
+		d.Set(k, v)
+	}
+
+	datapoints := make([]map[string]interface{}, len(metrics.Datapoints), len(metrics.Datapoints))
+	for i, v := range metrics.Datapoints {
+		datapoints[i] = flattenEdgeFunctionMetricsDatapoint(v)
+		datapoints[i]["timestamp"] = time.Unix(0, v.Timestamp*int64(time.Millisecond)).UTC().Format(time.RFC3339)
+	}
+	d.Set("datapoints", datapoints)
+
+	return nil
+}
+
+func flattenEdgeFunctionMetricsDatapoint(datapoint edgeFunctionMetricsDatapoint) map[string]interface{} {
+	errorRate := 0.0
+	if datapoint.Invocations > 0 {
+		errorRate = float64(datapoint.Errors) / float64(datapoint.Invocations)
+	}
+
+	m := make(map[string]interface{})
+	m["invocations"] = int(datapoint.Invocations)
+	m["errors"] = int(datapoint.Errors)
+	m["throttles"] = int(datapoint.Throttles)
+	m["error_rate"] = errorRate
+	m["duration_p50"] = datapoint.DurationP50
+	m["duration_p95"] = datapoint.DurationP95
+	m["duration_p99"] = datapoint.DurationP99
+	m["max_concurrency"] = int(datapoint.Concurrency)
+	return m
+}
@@ -0,0 +1,100 @@
+package limelight
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccDataSourceLimelightEdgeFunctionMetrics_basic(t *testing.T) {
+	testResourceName := "data.limelight_edgefunction_metrics.metrics"
+	fnName := "terraform_ef_test_metrics"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		CheckDestroy: func(state *terraform.State) error {
+			return testAccLimelightEdgeFunctionCheckDestroy(state, fnName)
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceLimelightEdgeFunctionMetricsBasicTemplate(fnName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet(testResourceName, "id"),
+					resource.TestCheckResourceAttrSet(testResourceName, "start_time"),
+					resource.TestCheckResourceAttrSet(testResourceName, "invocations"),
+					resource.TestCheckResourceAttrSet(testResourceName, "datapoints.#"),
+				),
+			},
+		},
+	})
+}
+
+func TestGetEdgeFunctionMetrics(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		json.NewEncoder(w).Encode(edgeFunctionMetrics{
+			Summary: edgeFunctionMetricsDatapoint{Invocations: 200, Errors: 3, DurationP99: 42.5},
+			Datapoints: []edgeFunctionMetricsDatapoint{
+				{Timestamp: 1588334400000, Invocations: 200, Errors: 3, DurationP99: 42.5},
+			},
+		})
+	}))
+	defer server.Close()
+
+	c := newEdgeFunctionsClient("user", "00", server.URL)
+	end := time.Date(2020, 5, 1, 13, 0, 0, 0, time.UTC)
+	metrics, _, err := getEdgeFunctionMetrics(c, "fn", "example", edgeFunctionMetricsQuery{
+		Alias:       "live",
+		Start:       end.Add(-time.Hour),
+		End:         end,
+		Granularity: 5 * time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if expected := "alias=live&end=1588338000000&granularity=300&start=1588334400000"; query != expected {
+		t.Errorf("expected query %s, got %s", expected, query)
+	}
+
+	summary := flattenEdgeFunctionMetricsDatapoint(metrics.Summary)
+	if summary["invocations"] != 200 || summary["errors"] != 3 || summary["error_rate"] != 0.015 || summary["duration_p99"] != 42.5 {
+		t.Errorf("unexpected summary %v", summary)
+	}
+	if len(metrics.Datapoints) != 1 {
+		t.Errorf("expected 1 datapoint, got %d", len(metrics.Datapoints))
+	}
+}
+
+func TestFlattenEdgeFunctionMetricsDatapointWithoutInvocations(t *testing.T) {
+	if errorRate := flattenEdgeFunctionMetricsDatapoint(edgeFunctionMetricsDatapoint{})["error_rate"]; errorRate != 0.0 {
+		t.Errorf("expected an error rate of 0 without invocations, got %v", errorRate)
+	}
+}
+
+func testAccDataSourceLimelightEdgeFunctionMetricsBasicTemplate(fnName string) string {
+	return fmt.Sprintf(`
+resource "limelight_edgefunction" "test_ef" {
+	shortname        = "%s"
+	name             = "%s"
+	function_archive = "testdata/edgefunc/py_function.zip"
+	handler          = "hello_world.handler"
+	runtime          = "python3"
+	function_sha256  = filesha256("testdata/edgefunc/py_function.zip")
+}
+
+data "limelight_edgefunction_metrics" "metrics" {
+	shortname     = limelight_edgefunction.test_ef.shortname
+	function_name = limelight_edgefunction.test_ef.name
+	window        = "15m"
+	granularity   = "1m"
+}`, getShortname(), fnName)
+}
@@ -29,7 +29,7 @@ func dataSourceLimelightIPRanges() *schema.Resource {
 }
 
 func dataSourceLimelightIPRangesRead(d *schema.ResourceData, m interface{}) error {
-	l := getConfigurationClient(m)
+	l := getMeta(m).IPAllowList
 	log.Printf("[INFO] Fetching IP ranges")
 	ipList, _, err := l.GetIPAllowList()
 	if err != nil {
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/llnw/llnw-sdk-go/configuration"
 )
 
 func TestAccDataSourceLimelightIPRanges_basic(t *testing.T) {
@@ -25,6 +26,24 @@ func TestAccDataSourceLimelightIPRanges_basic(t *testing.T) {
 	})
 }
 
+func TestDataSourceLimelightIPRangesRead(t *testing.T) {
+	m := newFakeMeta()
+	m.IPAllowList = &fakeIPAllowListAPI{ipList: &configuration.IPAllowList{
+		Version:  7,
+		IPRanges: []string{"192.0.2.0/24", "198.51.100.0/24"},
+	}}
+
+	r := dataSourceLimelightIPRanges()
+	d := r.Data(nil)
+	if err := r.Read(d, m); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if d.Id() == "" || d.Get("version") != 7 || d.Get("ip_ranges.#") != 2 || d.Get("ip_ranges.1") != "198.51.100.0/24" {
+		t.Errorf("unexpected state %v", d.State())
+	}
+}
+
 func testAccDataSourceLimelightIPRangesBasicTemplate() string {
 	return `
 data "limelight_ip_ranges" "ips" {}
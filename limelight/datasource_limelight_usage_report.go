@@ -0,0 +1,162 @@
+package limelight
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/hashcode"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+// usageReportSchema returns the attributes of the traffic of a published hostname, or
+// of all of them.
+func usageReportSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"bytes": &schema.Schema{
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"requests": &schema.Schema{
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"cache_hit_ratio": &schema.Schema{
+			Type:     schema.TypeFloat,
+			Computed: true,
+		},
+		"status_codes": &schema.Schema{
+			Type:     schema.TypeMap,
+			Computed: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeInt,
+			},
+		},
+	}
+}
+
+func dataSourceLimelightUsageReport() *schema.Resource {
+	hostnameSchema := usageReportSchema()
+	hostnameSchema["published_hostname"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+
+	s := map[string]*schema.Schema{
+		"shortname": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"start_time": &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			ValidateFunc: validation.IsRFC3339Time,
+		},
+		"end_time": &schema.Schema{
+			Type:         schema.TypeString,
+			Required:     true,
+			ValidateFunc: validation.IsRFC3339Time,
+		},
+		"published_hostnames": &schema.Schema{
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"output_file": &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"output_format": &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.StringInSlice(usageReportFormats, false),
+		},
+		"hostnames": &schema.Schema{
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: hostnameSchema,
+			},
+		},
+	}
+	// The totals over all hostnames have the same attributes as each hostname.
+	for k, v := range usageReportSchema() {
+		s[k] = v
+	}
+
+	return &schema.Resource{
+		Read:   dataSourceLimelightUsageReportRead,
+		Schema: s,
+	}
+}
+
+func dataSourceLimelightUsageReportRead(d *schema.ResourceData, m interface{}) error {
+	c := getReportingClient(m)
+
+	shortname, err := resolveShortname(d, m)
+	if err != nil {
+		return err
+	}
+
+	// The times were validated by the schema.
+	query := usageReportQuery{}
+	query.Start, _ = time.Parse(time.RFC3339, d.Get("start_time").(string))
+	query.End, _ = time.Parse(time.RFC3339, d.Get("end_time").(string))
+	if !query.Start.Before(query.End) {
+		return fmt.Errorf("start_time must be before end_time")
+	}
+	for _, v := range d.Get("published_hostnames").([]interface{}) {
+		query.PublishedHostnames = append(query.PublishedHostnames, v.(string))
+	}
+
+	log.Printf("[INFO] Fetching usage report for %s from %s to %s", shortname, query.Start.Format(time.RFC3339), query.End.Format(time.RFC3339))
+	report, _, err := c.GetUsageReport(shortname, query)
+	if err != nil {
+		return fmt.Errorf("error reading usage report: %s", err)
+	}
+
+	if path := d.Get("output_file").(string); path != "" {
+		format := usageReportFormat(d.Get("output_format").(string), path)
+		log.Printf("[INFO] Writing usage report to %s as %s", path, format)
+		if err := writeUsageReportFile(path, format, report); err != nil {
+			return err
+		}
+	}
+
+	d.SetId(strconv.Itoa(hashcode.String(strings.Join(append([]string{
+		shortname, query.Start.Format(time.RFC3339), query.End.Format(time.RFC3339),
+	}, query.PublishedHostnames...), ":"))))
+	d.Set("shortname", shortname)
+	for k, v := range flattenUsageReportRow(report.total()) {
+		d.Set(k, v)
+	}
+
+	hostnames := make([]map[string]interface{}, len(report.Results), len(report.Results))
+	for i, row := range report.Results {
+		hostnames[i] = flattenUsageReportRow(row)
+		hostnames[i]["published_hostname"] = row.PublishedHostname
+	}
+	d.Set("hostnames", hostnames)
+
+	return nil
+}
+
+func flattenUsageReportRow(row usageReportRow) map[string]interface{} {
+	statusCodes := make(map[string]interface{}, len(row.StatusCodes))
+	for code, count := range row.StatusCodes {
+		statusCodes[code] = int(count)
+	}
+
+	m := make(map[string]interface{})
+	m["bytes"] = int(row.Bytes)
+	m["requests"] = int(row.Requests)
+	m["cache_hit_ratio"] = row.cacheHitRatio()
+	m["status_codes"] = statusCodes
+	return m
+}
@@ -0,0 +1,153 @@
+package limelight
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestAccDataSourceLimelightUsageReport_basic(t *testing.T) {
+	testResourceName := "data.limelight_usage_report.report"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceLimelightUsageReportBasicTemplate(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet(testResourceName, "id"),
+					resource.TestCheckResourceAttrSet(testResourceName, "bytes"),
+					resource.TestCheckResourceAttrSet(testResourceName, "requests"),
+					resource.TestCheckResourceAttrSet(testResourceName, "hostnames.#"),
+				),
+			},
+		},
+	})
+}
+
+func testUsageReport() *usageReport {
+	return &usageReport{
+		Results: []usageReportRow{
+			{
+				PublishedHostname: "www.example.com",
+				Bytes:             1000,
+				Requests:          10,
+				CacheHits:         8,
+				StatusCodes:       map[string]int64{"200": 9, "404": 1},
+			},
+			{
+				PublishedHostname: "static.example.com",
+				Bytes:             500,
+				Requests:          4,
+				CacheHits:         1,
+				StatusCodes:       map[string]int64{"200": 3, "304": 1},
+			},
+		},
+	}
+}
+
+func TestGetUsageReport(t *testing.T) {
+	var path, query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		query = r.URL.RawQuery
+		json.NewEncoder(w).Encode(testUsageReport())
+	}))
+	defer server.Close()
+
+	c := newReportingClient("user", "00", server.URL)
+	start := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	report, _, err := c.GetUsageReport("example", usageReportQuery{
+		Start:              start,
+		End:                start.Add(24 * time.Hour),
+		PublishedHostnames: []string{"www.example.com", "static.example.com"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if expected := "/traffic/example"; path != expected {
+		t.Errorf("expected path %s, got %s", expected, path)
+	}
+	if expected := "end=1588377600000&publishedHostname=www.example.com&publishedHostname=static.example.com&start=1588291200000"; query != expected {
+		t.Errorf("expected query %s, got %s", expected, query)
+	}
+
+	total := flattenUsageReportRow(report.total())
+	if total["bytes"] != 1500 || total["requests"] != 14 || total["cache_hit_ratio"] != 9.0/14.0 {
+		t.Errorf("unexpected total %v", total)
+	}
+	statusCodes := total["status_codes"].(map[string]interface{})
+	if statusCodes["200"] != 12 || statusCodes["304"] != 1 || statusCodes["404"] != 1 {
+		t.Errorf("unexpected status codes %v", statusCodes)
+	}
+}
+
+func TestWriteUsageReportCSV(t *testing.T) {
+	var b bytes.Buffer
+	if err := writeUsageReport(&b, usageReportFormatCSV, testUsageReport()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := "published_hostname,bytes,requests,cache_hit_ratio,status_200,status_304,status_404\n" +
+		"www.example.com,1000,10,0.8,9,0,1\n" +
+		"static.example.com,500,4,0.25,3,1,0\n"
+	if b.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
+
+func TestWriteUsageReportJSON(t *testing.T) {
+	var b bytes.Buffer
+	if err := writeUsageReport(&b, usageReportFormatJSON, testUsageReport()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var rows []usageReportJSONRow
+	if err := json.Unmarshal(b.Bytes(), &rows); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(rows) != 2 || rows[1].PublishedHostname != "static.example.com" || rows[1].CacheHitRatio != 0.25 || rows[1].StatusCodes["304"] != 1 {
+		t.Errorf("unexpected rows %v", rows)
+	}
+}
+
+func TestUsageReportFormat(t *testing.T) {
+	cases := []struct {
+		format   string
+		path     string
+		expected string
+	}{
+		{"", "report.csv", usageReportFormatCSV},
+		{"", "report.JSON", usageReportFormatJSON},
+		{"", "", usageReportFormatCSV},
+		{usageReportFormatCSV, "report.json", usageReportFormatCSV},
+	}
+
+	for _, c := range cases {
+		if format := usageReportFormat(c.format, c.path); format != c.expected {
+			t.Errorf("expected format %s for (%q, %q), got %s", c.expected, c.format, c.path, format)
+		}
+	}
+
+	if err := writeUsageReport(&bytes.Buffer{}, "xml", testUsageReport()); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
+
+func testAccDataSourceLimelightUsageReportBasicTemplate() string {
+	end := time.Now().UTC().Truncate(time.Hour)
+	return fmt.Sprintf(`
+data "limelight_usage_report" "report" {
+	shortname  = "%s"
+	start_time = "%s"
+	end_time   = "%s"
+}`, getShortname(), end.Add(-24*time.Hour).Format(time.RFC3339), end.Format(time.RFC3339))
+}
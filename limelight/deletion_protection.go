@@ -0,0 +1,58 @@
+package limelight
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+// deletionProtectionSchema is the deletion_protection argument shared by the resources
+// that support it.
+func deletionProtectionSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeBool,
+		Optional: true,
+		Default:  false,
+	}
+}
+
+// checkDeletionProtection returns an error if deletion_protection is set in the state
+// of the resource being deleted.
+func checkDeletionProtection(d *schema.ResourceData, description string) error {
+	if d.Get("deletion_protection").(bool) {
+		return fmt.Errorf("cannot delete %s: deletion_protection is enabled. Set deletion_protection to false and apply before deleting it", description)
+	}
+	return nil
+}
+
+// customizeDiffDeletionProtection prevents changes to the given ForceNew arguments
+// while deletion_protection is set in the state, as they would replace the resource.
+// Disabling deletion_protection in the same change doesn't lift the protection: it has
+// to be applied first.
+func customizeDiffDeletionProtection(forceNewKeys ...string) schema.CustomizeDiffFunc {
+	return func(d *schema.ResourceDiff, m interface{}) error {
+		if d.Id() == "" {
+			return nil
+		}
+
+		protected, _ := d.GetChange("deletion_protection")
+		if !protected.(bool) {
+			return nil
+		}
+
+		for _, k := range forceNewKeys {
+			if d.HasChange(k) {
+				return fmt.Errorf("cannot change %s of %s, as that would replace it and deletion_protection is enabled. Set deletion_protection to false and apply first", k, d.Id())
+			}
+		}
+		return nil
+	}
+}
+
+// setDeletionProtectionDefault sets deletion_protection to its default when it isn't in
+// the state, e.g. after an import, so that importing doesn't produce a diff.
+func setDeletionProtectionDefault(d *schema.ResourceData) {
+	if _, ok := d.GetOkExists("deletion_protection"); !ok {
+		d.Set("deletion_protection", false)
+	}
+}
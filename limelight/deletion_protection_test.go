@@ -0,0 +1,105 @@
+package limelight
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestCheckDeletionProtection(t *testing.T) {
+	r := resourceLimelightEdgeFunctionAlias()
+
+	for _, protected := range []bool{true, false} {
+		d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+			"deletion_protection": protected,
+		})
+		err := checkDeletionProtection(d, "Alias test")
+		if protected && (err == nil || !strings.Contains(err.Error(), "deletion_protection")) {
+			t.Errorf("expected a deletion_protection error, got %v", err)
+		}
+		if !protected && err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	}
+}
+
+func TestCustomizeDiffDeletionProtection(t *testing.T) {
+	r := resourceLimelightEdgeFunctionAlias()
+
+	cases := []struct {
+		name          string
+		protected     string
+		config        map[string]interface{}
+		expectedError bool
+	}{
+		{
+			name:      "protected in-place update",
+			protected: "true",
+			config: map[string]interface{}{
+				"shortname":           "example",
+				"name":                "live",
+				"function_name":       "hello",
+				"function_version":    "2",
+				"deletion_protection": true,
+			},
+		},
+		{
+			name:      "protected replacement",
+			protected: "true",
+			config: map[string]interface{}{
+				"shortname":           "example",
+				"name":                "live",
+				"function_name":       "goodbye",
+				"function_version":    "1",
+				"deletion_protection": true,
+			},
+			expectedError: true,
+		},
+		{
+			name:      "replacement while disabling protection",
+			protected: "true",
+			config: map[string]interface{}{
+				"shortname":           "example",
+				"name":                "live",
+				"function_name":       "goodbye",
+				"function_version":    "1",
+				"deletion_protection": false,
+			},
+			expectedError: true,
+		},
+		{
+			name:      "unprotected replacement",
+			protected: "false",
+			config: map[string]interface{}{
+				"shortname":        "example",
+				"name":             "live",
+				"function_name":    "goodbye",
+				"function_version": "1",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		state := &terraform.InstanceState{
+			ID: "example:hello:live",
+			Attributes: map[string]string{
+				"id":                  "example:hello:live",
+				"shortname":           "example",
+				"name":                "live",
+				"function_name":       "hello",
+				"function_version":    "1",
+				"deletion_protection": c.protected,
+			},
+		}
+
+		_, err := r.Diff(state, terraform.NewResourceConfigRaw(c.config), nil)
+		if c.expectedError && (err == nil || !strings.Contains(err.Error(), "deletion_protection")) {
+			t.Errorf("%s: expected a deletion_protection error, got %v", c.name, err)
+		}
+		if !c.expectedError && err != nil {
+			t.Errorf("%s: unexpected error: %s", c.name, err)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package limelight
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// maxTailedEntries limits the log entries fetched by each poll of a tail.
+const maxTailedEntries = 10000
+
+// TailOptions configures TailEdgeFunctionLogs.
+type TailOptions struct {
+	Shortname    string
+	FunctionName string
+	Alias        string
+	RequestID    string
+	Level        string
+	// Since is how far back to start.
+	Since time.Duration
+	// Follow keeps polling for new entries every Interval until the process is stopped.
+	Follow   bool
+	Interval time.Duration
+}
+
+// TailEdgeFunctionLogs writes the log entries of an EdgeFunction to w, one per line. The
+// provider is configured from the environment, as it is for Terraform.
+func TailEdgeFunctionLogs(options TailOptions, w io.Writer) error {
+	if options.Shortname == "" || options.FunctionName == "" {
+		return fmt.Errorf("shortname and function name must be set")
+	}
+
+	provider, err := configureFromEnvironment()
+	if err != nil {
+		return err
+	}
+	c := getEdgeFunctionsClient(provider.Meta())
+
+	tailer := &edgeFunctionLogTailer{
+		query: edgeFunctionLogQuery{
+			Alias:     options.Alias,
+			RequestID: options.RequestID,
+			Level:     options.Level,
+			Start:     time.Now().Add(-options.Since),
+		},
+		fetch: func(query edgeFunctionLogQuery) ([]edgeFunctionLogEntry, error) {
+			return collectEdgeFunctionLogs(c, options.FunctionName, options.Shortname, query, maxTailedEntries)
+		},
+	}
+
+	for {
+		entries, err := tailer.poll(time.Now())
+		if err != nil {
+			return fmt.Errorf("error reading EdgeFunction logs: %s", err)
+		}
+
+		for _, entry := range entries {
+			fmt.Fprintf(w, "%s %-5s %s %s\n", entry.time().Format("2006-01-02T15:04:05.000Z07:00"), entry.Level, entry.RequestID, entry.Message)
+		}
+
+		if !options.Follow {
+			return nil
+		}
+		time.Sleep(options.Interval)
+	}
+}
+
+// edgeFunctionLogTailer polls for log entries, returning each entry once. Each poll
+// starts from the latest timestamp seen so far, as more entries with that timestamp may
+// arrive, and skips the entries with that timestamp that were already returned.
+type edgeFunctionLogTailer struct {
+	query edgeFunctionLogQuery
+	fetch func(query edgeFunctionLogQuery) ([]edgeFunctionLogEntry, error)
+	seen  map[edgeFunctionLogEntry]bool
+}
+
+func (t *edgeFunctionLogTailer) poll(now time.Time) ([]edgeFunctionLogEntry, error) {
+	query := t.query
+	query.End = now
+	entries, err := t.fetch(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var fresh []edgeFunctionLogEntry
+	var latest int64
+	for _, entry := range entries {
+		if !t.seen[entry] {
+			fresh = append(fresh, entry)
+		}
+		if entry.Timestamp > latest {
+			latest = entry.Timestamp
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	seen := map[edgeFunctionLogEntry]bool{}
+	for _, entry := range entries {
+		if entry.Timestamp == latest {
+			seen[entry] = true
+		}
+	}
+	t.seen = seen
+	t.query.Start = time.Unix(0, latest*int64(time.Millisecond))
+
+	return fresh, nil
+}
@@ -0,0 +1,69 @@
+package limelight
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEdgeFunctionLogTailer(t *testing.T) {
+	var available []edgeFunctionLogEntry
+	var starts []time.Time
+
+	tailer := &edgeFunctionLogTailer{
+		fetch: func(query edgeFunctionLogQuery) ([]edgeFunctionLogEntry, error) {
+			starts = append(starts, query.Start)
+			var entries []edgeFunctionLogEntry
+			for _, entry := range available {
+				if !entry.time().Before(query.Start) {
+					entries = append(entries, entry)
+				}
+			}
+			return entries, nil
+		},
+	}
+
+	polls := []struct {
+		arrived  []edgeFunctionLogEntry
+		expected []string
+	}{
+		{
+			arrived:  []edgeFunctionLogEntry{{Timestamp: 1000, Message: "a"}, {Timestamp: 2000, Message: "b"}},
+			expected: []string{"a", "b"},
+		},
+		{
+			arrived:  nil,
+			expected: nil,
+		},
+		{
+			// Arrived late with the same timestamp as the last entry returned.
+			arrived:  []edgeFunctionLogEntry{{Timestamp: 2000, Message: "c"}, {Timestamp: 3000, Message: "d"}},
+			expected: []string{"c", "d"},
+		},
+	}
+
+	for i, poll := range polls {
+		available = append(available, poll.arrived...)
+
+		entries, err := tailer.poll(time.Now())
+		if err != nil {
+			t.Fatalf("poll %d: unexpected error: %s", i, err)
+		}
+
+		var messages []string
+		for _, entry := range entries {
+			messages = append(messages, entry.Message)
+		}
+		if len(messages) != len(poll.expected) {
+			t.Fatalf("poll %d: expected %v, got %v", i, poll.expected, messages)
+		}
+		for j := range messages {
+			if messages[j] != poll.expected[j] {
+				t.Errorf("poll %d: expected %v, got %v", i, poll.expected, messages)
+			}
+		}
+	}
+
+	if !starts[2].Equal(time.Unix(2, 0)) {
+		t.Errorf("expected polls to resume from the latest timestamp, got %s", starts[2])
+	}
+}
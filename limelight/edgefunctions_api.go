@@ -0,0 +1,328 @@
+package limelight
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/llnw/llnw-sdk-go/edgefunctions"
+)
+
+const defaultEdgeFunctionsAPIBaseURL = "https://apis.llnw.com/ef-api/v1"
+
+// edgeFunctionsClient is a client for the Limelight EdgeFunctions API, implementing the
+// calls of the SDK client the provider uses.
+type edgeFunctionsClient struct {
+	*apiClient
+}
+
+func newEdgeFunctionsClient(apiUser string, apiKey string, baseURL string) *edgeFunctionsClient {
+	if baseURL == "" {
+		baseURL = defaultEdgeFunctionsAPIBaseURL
+	}
+
+	return &edgeFunctionsClient{newAPIClient(apiUser, apiKey, baseURL)}
+}
+
+func (c *edgeFunctionsClient) GetEdgeFunction(name string, shortname string) (*edgefunctions.EdgeFunction, *http.Response, error) {
+	body, response, err := c.get(fmt.Sprintf("%s/%s/functions/%s", c.BaseUrl, shortname, name))
+	if err != nil {
+		return nil, response, err
+	}
+
+	edgeFunction := &edgefunctions.EdgeFunction{}
+	if err = json.Unmarshal(body, edgeFunction); err != nil {
+		return nil, response, fmt.Errorf("error decoding EdgeFunction: %s", err)
+	}
+	return edgeFunction, response, nil
+}
+
+func (c *edgeFunctionsClient) GetEdgeFunctionAlias(fnName string, shortname string, aliasName string) (*edgefunctions.EdgeFunctionAlias, *http.Response, error) {
+	body, response, err := c.get(fmt.Sprintf("%s/%s/functions/%s/aliases/%s", c.BaseUrl, shortname, fnName, aliasName))
+	if err != nil {
+		return nil, response, err
+	}
+
+	alias := &edgefunctions.EdgeFunctionAlias{}
+	if err = json.Unmarshal(body, alias); err != nil {
+		return nil, response, fmt.Errorf("error decoding EdgeFunction alias: %s", err)
+	}
+	return alias, response, nil
+}
+
+// listEdgeFunctions lists the EdgeFunctions of an account, which the SDK doesn't support.
+func listEdgeFunctions(c *edgeFunctionsClient, shortname string) ([]edgefunctions.EdgeFunction, *http.Response, error) {
+	body, response, err := c.get(fmt.Sprintf("%s/%s/functions", c.BaseUrl, shortname))
+	if err != nil {
+		return nil, response, err
+	}
+
+	var functions []edgefunctions.EdgeFunction
+	if err = json.Unmarshal(body, &functions); err != nil {
+		return nil, response, fmt.Errorf("error decoding EdgeFunctions: %s", err)
+	}
+	return functions, response, nil
+}
+
+// listEdgeFunctionAliases lists the aliases of an EdgeFunction.
+func listEdgeFunctionAliases(c *edgeFunctionsClient, fnName string, shortname string) ([]edgefunctions.EdgeFunctionAlias, *http.Response, error) {
+	body, response, err := c.get(fmt.Sprintf("%s/%s/functions/%s/aliases", c.BaseUrl, shortname, fnName))
+	if err != nil {
+		return nil, response, err
+	}
+
+	var aliases []edgefunctions.EdgeFunctionAlias
+	if err = json.Unmarshal(body, &aliases); err != nil {
+		return nil, response, fmt.Errorf("error decoding EdgeFunction aliases: %s", err)
+	}
+	return aliases, response, nil
+}
+
+// The EdgeFunctions API calls below are bounded by the timeouts of the resources rather
+// than the read timeout.
+
+func createEdgeFunction(c *edgeFunctionsClient, shortname string, edgeFunction *edgefunctions.EdgeFunction, timeout time.Duration) (*edgefunctions.EdgeFunction, *http.Response, error) {
+	return sendEdgeFunction(c, http.MethodPost, fmt.Sprintf("%s/%s/functions", c.BaseUrl, shortname), edgeFunction, timeout)
+}
+
+func updateEdgeFunctionCode(c *edgeFunctionsClient, name string, shortname string, functionArchive []byte, timeout time.Duration) (*edgefunctions.EdgeFunction, *http.Response, error) {
+	edgeFunction := &edgefunctions.EdgeFunction{
+		FunctionArchive: functionArchive,
+	}
+	return sendEdgeFunction(c, http.MethodPut, fmt.Sprintf("%s/%s/functions/%s", c.BaseUrl, shortname, name), edgeFunction, timeout)
+}
+
+func updateEdgeFunctionConfiguration(c *edgeFunctionsClient, name string, shortname string, edgeFunction *edgefunctions.EdgeFunction, timeout time.Duration) (*edgefunctions.EdgeFunction, *http.Response, error) {
+	return sendEdgeFunction(c, http.MethodPut, fmt.Sprintf("%s/%s/functions/%s/configuration", c.BaseUrl, shortname, name), edgeFunction, timeout)
+}
+
+func sendEdgeFunction(c *edgeFunctionsClient, method string, url string, edgeFunction *edgefunctions.EdgeFunction, timeout time.Duration) (*edgefunctions.EdgeFunction, *http.Response, error) {
+	body, response, err := c.request(method, url, edgeFunction, timeout)
+	if err != nil {
+		return nil, response, err
+	}
+
+	// Like the SDK, tolerate an empty response.
+	edgeFunctionResponse := &edgefunctions.EdgeFunction{}
+	if len(body) == 0 {
+		return edgeFunctionResponse, response, nil
+	}
+	if err = json.Unmarshal(body, edgeFunctionResponse); err != nil {
+		return nil, response, fmt.Errorf("error decoding EdgeFunction: %s", err)
+	}
+	return edgeFunctionResponse, response, nil
+}
+
+func setEdgeFunctionConcurrency(c *edgeFunctionsClient, name string, shortname string, concurrency int, timeout time.Duration) (*http.Response, error) {
+	request := edgefunctions.ReservedConcurrency{ReservedConcurrency: concurrency}
+	_, response, err := c.request(http.MethodPut, fmt.Sprintf("%s/%s/functions/%s/concurrency", c.BaseUrl, shortname, name), request, timeout)
+	return response, err
+}
+
+func deleteEdgeFunction(c *edgeFunctionsClient, name string, shortname string, timeout time.Duration) (*http.Response, error) {
+	_, response, err := c.request(http.MethodDelete, fmt.Sprintf("%s/%s/functions/%s", c.BaseUrl, shortname, name), nil, timeout)
+	return response, err
+}
+
+func createEdgeFunctionAlias(c *edgeFunctionsClient, fnName string, shortname string, alias *edgefunctions.EdgeFunctionAlias, timeout time.Duration) (*edgefunctions.EdgeFunctionAlias, *http.Response, error) {
+	return sendEdgeFunctionAlias(c, http.MethodPost, fmt.Sprintf("%s/%s/functions/%s/aliases", c.BaseUrl, shortname, fnName), alias, timeout)
+}
+
+func updateEdgeFunctionAlias(c *edgeFunctionsClient, fnName string, shortname string, aliasName string, alias *edgefunctions.EdgeFunctionAlias, timeout time.Duration) (*edgefunctions.EdgeFunctionAlias, *http.Response, error) {
+	return sendEdgeFunctionAlias(c, http.MethodPut, fmt.Sprintf("%s/%s/functions/%s/aliases/%s", c.BaseUrl, shortname, fnName, aliasName), alias, timeout)
+}
+
+func sendEdgeFunctionAlias(c *edgeFunctionsClient, method string, url string, alias *edgefunctions.EdgeFunctionAlias, timeout time.Duration) (*edgefunctions.EdgeFunctionAlias, *http.Response, error) {
+	body, response, err := c.request(method, url, alias, timeout)
+	if err != nil {
+		return nil, response, err
+	}
+
+	aliasResponse := &edgefunctions.EdgeFunctionAlias{}
+	if err = json.Unmarshal(body, aliasResponse); err != nil {
+		return nil, response, fmt.Errorf("error decoding EdgeFunction alias: %s", err)
+	}
+	return aliasResponse, response, nil
+}
+
+func deleteEdgeFunctionAlias(c *edgeFunctionsClient, fnName string, shortname string, aliasName string, timeout time.Duration) (*http.Response, error) {
+	_, response, err := c.request(http.MethodDelete, fmt.Sprintf("%s/%s/functions/%s/aliases/%s", c.BaseUrl, shortname, fnName, aliasName), nil, timeout)
+	return response, err
+}
+
+// edgeFunctionLogQuery filters the log entries of an EdgeFunction. Zero values aren't
+// sent.
+type edgeFunctionLogQuery struct {
+	Alias     string
+	Start     time.Time
+	End       time.Time
+	RequestID string
+	Level     string
+	Limit     int
+	NextToken string
+}
+
+func (q edgeFunctionLogQuery) values() url.Values {
+	values := url.Values{}
+	if q.Alias != "" {
+		values.Set("alias", q.Alias)
+	}
+	if !q.Start.IsZero() {
+		values.Set("start", strconv.FormatInt(q.Start.UnixNano()/int64(time.Millisecond), 10))
+	}
+	if !q.End.IsZero() {
+		values.Set("end", strconv.FormatInt(q.End.UnixNano()/int64(time.Millisecond), 10))
+	}
+	if q.RequestID != "" {
+		values.Set("requestId", q.RequestID)
+	}
+	if q.Level != "" {
+		values.Set("level", q.Level)
+	}
+	if q.Limit > 0 {
+		values.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if q.NextToken != "" {
+		values.Set("nextToken", q.NextToken)
+	}
+	return values
+}
+
+type edgeFunctionLogEntry struct {
+	// Timestamp is in milliseconds since the epoch.
+	Timestamp int64  `json:"timestamp"`
+	RequestID string `json:"requestId"`
+	Level     string `json:"level"`
+	Message   string `json:"message"`
+}
+
+func (e edgeFunctionLogEntry) time() time.Time {
+	return time.Unix(0, e.Timestamp*int64(time.Millisecond)).UTC()
+}
+
+type edgeFunctionLogPage struct {
+	Entries   []edgeFunctionLogEntry `json:"entries"`
+	NextToken string                 `json:"nextToken"`
+}
+
+// getEdgeFunctionLogs fetches a page of the log entries of an EdgeFunction, oldest first.
+func getEdgeFunctionLogs(c *edgeFunctionsClient, fnName string, shortname string, query edgeFunctionLogQuery) (*edgeFunctionLogPage, *http.Response, error) {
+	requestURL := fmt.Sprintf("%s/%s/functions/%s/logs", c.BaseUrl, shortname, fnName)
+	if values := query.values(); len(values) > 0 {
+		requestURL += "?" + values.Encode()
+	}
+
+	body, response, err := c.get(requestURL)
+	if err != nil {
+		return nil, response, err
+	}
+
+	page := &edgeFunctionLogPage{}
+	if err = json.Unmarshal(body, page); err != nil {
+		return nil, response, fmt.Errorf("error decoding EdgeFunction logs: %s", err)
+	}
+	return page, response, nil
+}
+
+// collectEdgeFunctionLogs follows the pages of log entries until maxEntries entries are
+// collected or there are no more pages, keeping the earliest entries so that a tail can
+// carry on from the last one.
+func collectEdgeFunctionLogs(c *edgeFunctionsClient, fnName string, shortname string, query edgeFunctionLogQuery, maxEntries int) ([]edgeFunctionLogEntry, error) {
+	var entries []edgeFunctionLogEntry
+
+	for {
+		query.Limit = maxEntries - len(entries)
+		page, _, err := getEdgeFunctionLogs(c, fnName, shortname, query)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, page.Entries...)
+		if len(entries) >= maxEntries {
+			return entries[:maxEntries], nil
+		}
+		if page.NextToken == "" || len(page.Entries) == 0 {
+			return entries, nil
+		}
+		query.NextToken = page.NextToken
+	}
+}
+
+// collectLatestEdgeFunctionLogs follows all the pages of log entries, and returns the
+// latest maxEntries entries, oldest first.
+func collectLatestEdgeFunctionLogs(c *edgeFunctionsClient, fnName string, shortname string, query edgeFunctionLogQuery, maxEntries int) ([]edgeFunctionLogEntry, error) {
+	var entries []edgeFunctionLogEntry
+
+	query.Limit = maxEntries
+	for {
+		page, _, err := getEdgeFunctionLogs(c, fnName, shortname, query)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, page.Entries...)
+		if len(entries) > maxEntries {
+			entries = append(entries[:0], entries[len(entries)-maxEntries:]...)
+		}
+		if page.NextToken == "" || len(page.Entries) == 0 {
+			return entries, nil
+		}
+		query.NextToken = page.NextToken
+	}
+}
+
+// edgeFunctionMetricsQuery selects the invocation metrics of an EdgeFunction.
+type edgeFunctionMetricsQuery struct {
+	Alias       string
+	Start       time.Time
+	End         time.Time
+	Granularity time.Duration
+}
+
+func (q edgeFunctionMetricsQuery) values() url.Values {
+	values := url.Values{}
+	if q.Alias != "" {
+		values.Set("alias", q.Alias)
+	}
+	values.Set("start", strconv.FormatInt(q.Start.UnixNano()/int64(time.Millisecond), 10))
+	values.Set("end", strconv.FormatInt(q.End.UnixNano()/int64(time.Millisecond), 10))
+	values.Set("granularity", strconv.FormatInt(int64(q.Granularity/time.Second), 10))
+	return values
+}
+
+// edgeFunctionMetricsDatapoint holds the metrics of an interval. Durations are in
+// milliseconds, and concurrency is the highest number of concurrent invocations.
+type edgeFunctionMetricsDatapoint struct {
+	// Timestamp is the start of the interval, in milliseconds since the epoch.
+	Timestamp   int64   `json:"timestamp"`
+	Invocations int64   `json:"invocations"`
+	Errors      int64   `json:"errors"`
+	Throttles   int64   `json:"throttles"`
+	DurationP50 float64 `json:"durationP50"`
+	DurationP95 float64 `json:"durationP95"`
+	DurationP99 float64 `json:"durationP99"`
+	Concurrency int64   `json:"concurrency"`
+}
+
+// edgeFunctionMetrics holds the metrics of each interval of a window, and of the window
+// as a whole.
+type edgeFunctionMetrics struct {
+	Summary    edgeFunctionMetricsDatapoint   `json:"summary"`
+	Datapoints []edgeFunctionMetricsDatapoint `json:"datapoints"`
+}
+
+func getEdgeFunctionMetrics(c *edgeFunctionsClient, fnName string, shortname string, query edgeFunctionMetricsQuery) (*edgeFunctionMetrics, *http.Response, error) {
+	body, response, err := c.get(fmt.Sprintf("%s/%s/functions/%s/metrics?%s", c.BaseUrl, shortname, fnName, query.values().Encode()))
+	if err != nil {
+		return nil, response, err
+	}
+
+	metrics := &edgeFunctionMetrics{}
+	if err = json.Unmarshal(body, metrics); err != nil {
+		return nil, response, fmt.Errorf("error decoding EdgeFunction metrics: %s", err)
+	}
+	return metrics, response, nil
+}
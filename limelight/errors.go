@@ -0,0 +1,106 @@
+package limelight
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+// apiErrorKind classifies a failed API call, so that the resources react to the same
+// failures in the same way.
+type apiErrorKind int
+
+const (
+	apiErrorOther apiErrorKind = iota
+	// apiErrorNotFound means the object doesn't exist (any more).
+	apiErrorNotFound
+	// apiErrorInUse means the object can't be deleted or changed because other objects
+	// depend on it.
+	apiErrorInUse
+	// apiErrorRetryable means the API didn't handle the request, and asks for it to be
+	// sent again later.
+	apiErrorRetryable
+)
+
+// maxErrorBodySize limits how much of an error response is read to classify it.
+const maxErrorBodySize = 64 * 1024
+
+var inUsePattern = regexp.MustCompile(`(?i)\b(in[ -]use|still (used|referenced)|is (used|referenced) by|has dependen)`)
+
+// classifyAPIError classifies the error and response of an API call. The SDK includes
+// the body of error responses in the error for requests with a body, and leaves it
+// unread otherwise, so both are inspected.
+func classifyAPIError(resp *http.Response, err error) apiErrorKind {
+	if err == nil || resp == nil {
+		return apiErrorOther
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusGone:
+		return apiErrorNotFound
+	case http.StatusConflict:
+		return apiErrorInUse
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable:
+		return apiErrorRetryable
+	}
+
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+		if inUsePattern.MatchString(err.Error()) || inUsePattern.Match(readErrorBody(resp)) {
+			return apiErrorInUse
+		}
+	}
+
+	return apiErrorOther
+}
+
+func isNotFoundError(resp *http.Response, err error) bool {
+	return classifyAPIError(resp, err) == apiErrorNotFound
+}
+
+// readErrorBody reads and closes the unread body of an error response, leaving a copy
+// in its place.
+func readErrorBody(resp *http.Response) []byte {
+	if resp.Body == nil {
+		return nil
+	}
+
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body
+}
+
+// handleReadError handles the error of reading a resource: a resource that no longer
+// exists is removed from the state, any other error is returned.
+func handleReadError(d *schema.ResourceData, resp *http.Response, err error, description string) error {
+	if isNotFoundError(resp, err) {
+		log.Printf("[INFO] %s not found, removing it from the state", description)
+		d.SetId("")
+		return nil
+	}
+	return fmt.Errorf("error reading %s: %s", description, err)
+}
+
+// handleDeleteError handles the error of deleting a resource. Deleting a resource that
+// no longer exists succeeds, so that objects removed outside of Terraform don't block a
+// destroy.
+func handleDeleteError(resp *http.Response, err error, description string) error {
+	if err == nil {
+		return nil
+	}
+
+	switch classifyAPIError(resp, err) {
+	case apiErrorNotFound:
+		log.Printf("[INFO] %s was already deleted", description)
+		return nil
+	case apiErrorInUse:
+		return fmt.Errorf("cannot delete %s because it is still in use, e.g. by an alias or another configuration that refers to it. Remove or update what uses it first: %s", description, err)
+	}
+	return fmt.Errorf("error deleting %s: %s", description, err)
+}
@@ -0,0 +1,72 @@
+package limelight
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func testErrorResponse(statusCode int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: statusCode,
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestClassifyAPIError(t *testing.T) {
+	err := errors.New("non-2XX status code from API, got status 400")
+
+	cases := []struct {
+		name     string
+		resp     *http.Response
+		err      error
+		expected apiErrorKind
+	}{
+		{"success", testErrorResponse(http.StatusOK, ""), nil, apiErrorOther},
+		{"no response", nil, err, apiErrorOther},
+		{"not found", testErrorResponse(http.StatusNotFound, ""), err, apiErrorNotFound},
+		{"gone", testErrorResponse(http.StatusGone, ""), err, apiErrorNotFound},
+		{"conflict", testErrorResponse(http.StatusConflict, ""), err, apiErrorInUse},
+		{"in use in body", testErrorResponse(http.StatusBadRequest, `{"message": "Function is in use by alias live"}`), err, apiErrorInUse},
+		{"in use in error", testErrorResponse(http.StatusBadRequest, ""), errors.New("non-200 status code from API, got status 400: resource still referenced"), apiErrorInUse},
+		{"other client error", testErrorResponse(http.StatusBadRequest, `{"message": "invalid name"}`), err, apiErrorOther},
+		{"server error", testErrorResponse(http.StatusInternalServerError, "in use"), err, apiErrorOther},
+		{"throttled", testErrorResponse(http.StatusTooManyRequests, ""), err, apiErrorRetryable},
+		{"unavailable", testErrorResponse(http.StatusServiceUnavailable, ""), err, apiErrorRetryable},
+	}
+
+	for _, c := range cases {
+		if actual := classifyAPIError(c.resp, c.err); actual != c.expected {
+			t.Errorf("%s: expected %d, got %d", c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestClassifyAPIErrorKeepsBody(t *testing.T) {
+	resp := testErrorResponse(http.StatusBadRequest, "invalid")
+	classifyAPIError(resp, errors.New("failed"))
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	if string(body) != "invalid" {
+		t.Errorf("expected the body to still be readable, got %q", body)
+	}
+}
+
+func TestHandleDeleteError(t *testing.T) {
+	err := errors.New("non-2XX status code from API, got status 404")
+
+	if actual := handleDeleteError(testErrorResponse(http.StatusNotFound, ""), err, "EdgeFunction test"); actual != nil {
+		t.Errorf("expected deleting a missing object to succeed, got %s", actual)
+	}
+	if actual := handleDeleteError(testErrorResponse(http.StatusGone, ""), err, "EdgeFunction test"); actual != nil {
+		t.Errorf("expected deleting a removed object to succeed, got %s", actual)
+	}
+	if actual := handleDeleteError(testErrorResponse(http.StatusConflict, ""), err, "EdgeFunction test"); actual == nil || !strings.Contains(actual.Error(), "still in use") {
+		t.Errorf("expected an in use error, got %v", actual)
+	}
+	if actual := handleDeleteError(testErrorResponse(http.StatusInternalServerError, ""), err, "EdgeFunction test"); actual == nil || !strings.HasPrefix(actual.Error(), "error deleting EdgeFunction test") {
+		t.Errorf("expected a delete error, got %v", actual)
+	}
+}
@@ -0,0 +1,331 @@
+package limelight
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	exportConfigFile       = "limelight.tf"
+	exportImportBlocksFile = "imports.tf"
+	exportImportScriptFile = "import.sh"
+)
+
+// Export writes Terraform configuration for the delivery configurations, EdgeFunctions and
+// EdgeFunction aliases of an account to outputDir, along with a script of terraform import
+// commands to bring them under management. With importBlocks, import blocks are written
+// too: they are only supported by Terraform 1.5 and later, and fail earlier versions when
+// the file is in the configuration's directory. The provider is configured from the
+// environment, as it is for Terraform.
+//
+// The configuration is generated from the same Read functions and schemas as the
+// resources, so that importing the resources produces an empty plan. EdgeFunction code
+// can't be read back, so the archive matching function_sha256 has to be saved at the
+// function_archive path by hand. A change of function_archive alone isn't planned, so
+// the path doesn't show up in the plan after importing.
+func Export(shortname string, outputDir string, importBlocks bool) error {
+	if shortname == "" {
+		return fmt.Errorf("shortname must be set")
+	}
+
+	provider, err := configureFromEnvironment()
+	if err != nil {
+		return err
+	}
+
+	e := newExporter(provider)
+	if err := e.exportAccount(shortname); err != nil {
+		return err
+	}
+
+	return e.write(outputDir, importBlocks)
+}
+
+// exporter accumulates the generated configuration, import blocks and import script.
+type exporter struct {
+	provider *schema.Provider
+	config   *hclwrite.File
+	imports  *hclwrite.File
+	script   bytes.Buffer
+	names    map[string]int
+}
+
+func newExporter(provider *schema.Provider) *exporter {
+	e := &exporter{
+		provider: provider,
+		config:   hclwrite.NewEmptyFile(),
+		imports:  hclwrite.NewEmptyFile(),
+		names:    map[string]int{},
+	}
+	e.script.WriteString("#!/bin/sh\nset -e\n\n")
+	return e
+}
+
+func (e *exporter) exportAccount(shortname string) error {
+	m := e.provider.Meta()
+
+	log.Printf("[INFO] Listing delivery configurations for: %s", shortname)
+	deliveries, _, err := listServiceInstances(getConfigurationClient(m), deliveryServiceKey, shortname)
+	if err != nil {
+		return fmt.Errorf("error listing delivery configurations: %s", err)
+	}
+
+	for _, delivery := range deliveries {
+		body := map[string]interface{}{}
+		if err := delivery.decodeBody(&body); err != nil {
+			return fmt.Errorf("error reading delivery configuration %s: %s", delivery.UUID, err)
+		}
+		name := fmt.Sprintf("%v%v", body["publishedHostname"], body["publishedUrlPath"])
+		if _, err := e.exportResource("limelight_delivery", name, delivery.UUID); err != nil {
+			return err
+		}
+	}
+
+	c := getEdgeFunctionsClient(m)
+
+	log.Printf("[INFO] Listing EdgeFunctions for: %s", shortname)
+	functions, _, err := listEdgeFunctions(c, shortname)
+	if err != nil {
+		return fmt.Errorf("error listing EdgeFunctions: %s", err)
+	}
+
+	for _, function := range functions {
+		block, err := e.exportResource("limelight_edgefunction", function.Name, fmt.Sprintf("%s:%s", shortname, function.Name))
+		if err != nil {
+			return err
+		}
+		if block != nil {
+			archive := fmt.Sprintf("functions/%s.zip", function.Name)
+			block.Body().SetAttributeValue("function_archive", cty.StringVal(archive))
+			block.Body().AppendUnstructuredTokens(exportComment(
+				fmt.Sprintf("The function code can't be exported: save the archive matching function_sha256 as %s.", archive)))
+		}
+
+		log.Printf("[INFO] Listing aliases for EdgeFunction: %s", function.Name)
+		aliases, _, err := listEdgeFunctionAliases(c, function.Name, shortname)
+		if err != nil {
+			return fmt.Errorf("error listing aliases for EdgeFunction %s: %s", function.Name, err)
+		}
+
+		for _, alias := range aliases {
+			id := fmt.Sprintf("%s:%s:%s", shortname, function.Name, alias.Name)
+			if _, err := e.exportResource("limelight_edgefunction_alias", function.Name+"_"+alias.Name, id); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// exportResource reads a resource as terraform import would, and appends its
+// configuration, import block and import command. It returns the resource block, or nil
+// if the resource no longer exists.
+func (e *exporter) exportResource(resourceType string, name string, id string) (*hclwrite.Block, error) {
+	r := e.provider.ResourcesMap[resourceType]
+
+	d := r.Data(nil)
+	d.SetId(id)
+	if err := r.Read(d, e.provider.Meta()); err != nil {
+		return nil, err
+	}
+	if d.Id() == "" {
+		log.Printf("[WARN] %s %s disappeared during export", resourceType, id)
+		return nil, nil
+	}
+
+	name = e.uniqueName(name)
+
+	block := e.config.Body().AppendNewBlock("resource", []string{resourceType, name})
+	writeHCLBody(block.Body(), r.Schema, resourceDataValues(r.Schema, d))
+	e.config.Body().AppendNewline()
+
+	importBlock := e.imports.Body().AppendNewBlock("import", nil)
+	importBlock.Body().SetAttributeTraversal("to", hcl.Traversal{
+		hcl.TraverseRoot{Name: resourceType},
+		hcl.TraverseAttr{Name: name},
+	})
+	importBlock.Body().SetAttributeValue("id", cty.StringVal(id))
+	e.imports.Body().AppendNewline()
+
+	fmt.Fprintf(&e.script, "terraform import %s %s\n", shellQuote(resourceType+"."+name), shellQuote(id))
+
+	return block, nil
+}
+
+var invalidHCLNameCharacters = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// uniqueName converts s to a resource name that is unique within the export.
+func (e *exporter) uniqueName(s string) string {
+	name := strings.Trim(invalidHCLNameCharacters.ReplaceAllString(strings.ToLower(s), "_"), "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "r_" + name
+	}
+
+	e.names[name]++
+	if n := e.names[name]; n > 1 {
+		return fmt.Sprintf("%s_%d", name, n)
+	}
+	return name
+}
+
+// exportFile is a file written by the export.
+type exportFile struct {
+	name    string
+	content []byte
+	mode    os.FileMode
+}
+
+// write writes the configuration and import script to outputDir, and the import blocks
+// with importBlocks.
+func (e *exporter) write(outputDir string, importBlocks bool) error {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return err
+	}
+
+	files := []exportFile{
+		{exportConfigFile, e.config.Bytes(), 0644},
+		{exportImportScriptFile, e.script.Bytes(), 0755},
+	}
+	if importBlocks {
+		files = append(files, exportFile{exportImportBlocksFile, e.imports.Bytes(), 0644})
+	}
+
+	for _, f := range files {
+		path := filepath.Join(outputDir, f.name)
+		if err := ioutil.WriteFile(path, f.content, f.mode); err != nil {
+			return fmt.Errorf("error writing %s: %s", path, err)
+		}
+		log.Printf("[INFO] Wrote %s", path)
+	}
+
+	return nil
+}
+
+// resourceDataValues returns the values of the top-level attributes of a resource.
+func resourceDataValues(schemaMap map[string]*schema.Schema, d *schema.ResourceData) map[string]interface{} {
+	values := make(map[string]interface{}, len(schemaMap))
+	for k := range schemaMap {
+		values[k] = d.Get(k)
+	}
+	return values
+}
+
+// writeHCLBody writes the arguments in values to body, nested resources as blocks.
+// Computed-only attributes, and optional arguments that are unset or set to their
+// default, are left out.
+func writeHCLBody(body *hclwrite.Body, schemaMap map[string]*schema.Schema, values map[string]interface{}) {
+	var attributes, blocks []string
+	for k, s := range schemaMap {
+		if !s.Required && !s.Optional {
+			continue
+		}
+		if _, ok := s.Elem.(*schema.Resource); ok {
+			blocks = append(blocks, k)
+		} else {
+			attributes = append(attributes, k)
+		}
+	}
+	sort.Strings(attributes)
+	sort.Strings(blocks)
+
+	for _, k := range attributes {
+		s := schemaMap[k]
+		v := values[k]
+		if !s.Required && (isZeroHCLValue(v) || reflect.DeepEqual(v, s.Default)) {
+			continue
+		}
+		body.SetAttributeValue(k, hclValue(v))
+	}
+
+	for _, k := range blocks {
+		elem := schemaMap[k].Elem.(*schema.Resource)
+		for _, element := range hclList(values[k]) {
+			block := body.AppendNewBlock(k, nil)
+			writeHCLBody(block.Body(), elem.Schema, element.(map[string]interface{}))
+		}
+	}
+}
+
+func hclList(v interface{}) []interface{} {
+	switch v := v.(type) {
+	case *schema.Set:
+		return v.List()
+	case []interface{}:
+		return v
+	}
+	return nil
+}
+
+func isZeroHCLValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	if set, ok := v.(*schema.Set); ok {
+		return set.Len() == 0
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	}
+	return reflect.DeepEqual(v, reflect.Zero(rv.Type()).Interface())
+}
+
+// hclValue converts a value read from a schema.ResourceData to a cty.Value.
+func hclValue(v interface{}) cty.Value {
+	switch v := v.(type) {
+	case string:
+		return cty.StringVal(v)
+	case int:
+		return cty.NumberIntVal(int64(v))
+	case float64:
+		return cty.NumberFloatVal(v)
+	case bool:
+		return cty.BoolVal(v)
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return cty.EmptyObjectVal
+		}
+		values := make(map[string]cty.Value, len(v))
+		for k, element := range v {
+			values[k] = hclValue(element)
+		}
+		return cty.ObjectVal(values)
+	}
+
+	list := hclList(v)
+	if len(list) == 0 {
+		return cty.EmptyTupleVal
+	}
+	values := make([]cty.Value, len(list))
+	for i, element := range list {
+		values[i] = hclValue(element)
+	}
+	return cty.TupleVal(values)
+}
+
+func exportComment(text string) hclwrite.Tokens {
+	return hclwrite.Tokens{
+		{Type: hclsyntax.TokenComment, Bytes: []byte("# " + text + "\n")},
+	}
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
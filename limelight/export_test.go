@@ -0,0 +1,116 @@
+package limelight
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestWriteHCLBody(t *testing.T) {
+	r := resourceLimelightDelivery()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"shortname":          "example",
+		"service_profile":    "LLNW-Generic",
+		"published_hostname": "www.example.com",
+		"published_path":     "/",
+		"source_hostname":    "origin.example.com",
+		"source_path":        "/${path}",
+		"protocol_set": []interface{}{
+			map[string]interface{}{
+				"published_protocol": "https",
+				"source_protocol":    "https",
+				"source_port":        8443,
+				"option": []interface{}{
+					map[string]interface{}{
+						"name":       "reply_send_header",
+						"parameters": []interface{}{"X-Test", "1"},
+					},
+				},
+			},
+		},
+	})
+
+	file := hclwrite.NewEmptyFile()
+	writeHCLBody(file.Body(), r.Schema, resourceDataValues(r.Schema, d))
+
+	expected := `published_hostname = "www.example.com"
+published_path     = "/"
+service_profile    = "LLNW-Generic"
+shortname          = "example"
+source_hostname    = "origin.example.com"
+source_path        = "/$${path}"
+protocol_set {
+  published_protocol = "https"
+  source_port        = 8443
+  source_protocol    = "https"
+  option {
+    name       = "reply_send_header"
+    parameters = ["X-Test", "1"]
+  }
+}
+`
+	if actual := string(file.Bytes()); actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
+
+func TestExporterUniqueName(t *testing.T) {
+	e := newExporter(nil)
+
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"www.example.com/", "www_example_com"},
+		{"www.example.com/", "www_example_com_2"},
+		{"WWW.Example.com", "www_example_com_3"},
+		{"1.example.com/images", "r_1_example_com_images"},
+		{"", "r_"},
+	}
+
+	for _, c := range cases {
+		if actual := e.uniqueName(c.input); actual != c.expected {
+			t.Errorf("uniqueName(%q): expected %q, got %q", c.input, c.expected, actual)
+		}
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	if actual, expected := shellQuote("it's"), `'it'\''s'`; actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
+
+func TestExporterWriteImportBlocks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "limelight-export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	e := newExporter(nil)
+	e.imports.Body().AppendNewBlock("import", nil)
+
+	if err := e.write(dir, false); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{exportConfigFile, exportImportScriptFile} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s to be written: %s", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, exportImportBlocksFile)); !os.IsNotExist(err) {
+		t.Errorf("expected %s not to be written without import blocks, got %v", exportImportBlocksFile, err)
+	}
+
+	if err := e.write(dir, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, exportImportBlocksFile)); err != nil {
+		t.Errorf("expected %s to be written with import blocks: %s", exportImportBlocksFile, err)
+	}
+}
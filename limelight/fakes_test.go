@@ -0,0 +1,420 @@
+package limelight
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/llnw/llnw-sdk-go/configuration"
+	"github.com/llnw/llnw-sdk-go/edgefunctions"
+)
+
+// fakeAPIError returns the response and error of an API call that failed with status.
+func fakeAPIError(status int) (*http.Response, error) {
+	resp := &http.Response{
+		StatusCode: status,
+		Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+	}
+	return resp, fmt.Errorf("non-2XX status code from API, got status %d", status)
+}
+
+// fakeFailures makes the calls to the methods of a fake fail with a status code.
+type fakeFailures map[string]int
+
+func (f fakeFailures) fail(method string) (*http.Response, error) {
+	if status, ok := f[method]; ok {
+		return fakeAPIError(status)
+	}
+	return nil, nil
+}
+
+// fakeDeliveryAPI is an in-memory DeliveryAPI, keeping every revision of each delivery.
+type fakeDeliveryAPI struct {
+	revisions map[string][]serviceInstance
+	failures  fakeFailures
+	nextID    int
+}
+
+func newFakeDeliveryAPI() *fakeDeliveryAPI {
+	return &fakeDeliveryAPI{revisions: map[string][]serviceInstance{}, failures: fakeFailures{}}
+}
+
+func (f *fakeDeliveryAPI) latest(uuid string) *serviceInstance {
+	revisions := f.revisions[uuid]
+	if len(revisions) == 0 {
+		return nil
+	}
+	latest := revisions[len(revisions)-1]
+	return &latest
+}
+
+func (f *fakeDeliveryAPI) save(uuid string, body map[string]interface{}, shortname string) *serviceInstance {
+	for i := range f.revisions[uuid] {
+		f.revisions[uuid][i].IsLatest = false
+	}
+	instance := serviceInstance{
+		UUID:      uuid,
+		IsLatest:  true,
+		Revision:  configuration.Revision{VersionNumber: len(f.revisions[uuid]) + 1},
+		Shortname: shortname,
+		Body:      body,
+	}
+	f.revisions[uuid] = append(f.revisions[uuid], instance)
+	return &instance
+}
+
+func (f *fakeDeliveryAPI) GetConfigurationOptions(shortname string, profileName string) ([]configuration.ConfigOption, *http.Response, error) {
+	if resp, err := f.failures.fail("GetConfigurationOptions"); err != nil {
+		return nil, resp, err
+	}
+	return nil, nil, nil
+}
+
+func (f *fakeDeliveryAPI) GetDelivery(uuid string) (*serviceInstance, *http.Response, error) {
+	if resp, err := f.failures.fail("GetDelivery"); err != nil {
+		return nil, resp, err
+	}
+	instance := f.latest(uuid)
+	if instance == nil {
+		resp, err := fakeAPIError(http.StatusNotFound)
+		return nil, resp, err
+	}
+	return instance, nil, nil
+}
+
+func (f *fakeDeliveryAPI) GetDeliveryRevisions(uuid string) ([]serviceInstance, *http.Response, error) {
+	if resp, err := f.failures.fail("GetDeliveryRevisions"); err != nil {
+		return nil, resp, err
+	}
+	if _, ok := f.revisions[uuid]; !ok {
+		resp, err := fakeAPIError(http.StatusNotFound)
+		return nil, resp, err
+	}
+	return f.revisions[uuid], nil, nil
+}
+
+func (f *fakeDeliveryAPI) CreateDelivery(body map[string]interface{}, shortname string, timeout time.Duration) (*serviceInstance, *http.Response, error) {
+	if resp, err := f.failures.fail("CreateDelivery"); err != nil {
+		return nil, resp, err
+	}
+	f.nextID++
+	return f.save(fmt.Sprintf("uuid-%d", f.nextID), body, shortname), nil, nil
+}
+
+func (f *fakeDeliveryAPI) UpdateDelivery(uuid string, body map[string]interface{}, shortname string, timeout time.Duration) (*serviceInstance, *http.Response, error) {
+	if resp, err := f.failures.fail("UpdateDelivery"); err != nil {
+		return nil, resp, err
+	}
+	if f.latest(uuid) == nil {
+		resp, err := fakeAPIError(http.StatusNotFound)
+		return nil, resp, err
+	}
+	return f.save(uuid, body, shortname), nil, nil
+}
+
+func (f *fakeDeliveryAPI) DeleteDelivery(uuid string, timeout time.Duration) (*http.Response, error) {
+	if resp, err := f.failures.fail("DeleteDelivery"); err != nil {
+		return resp, err
+	}
+	if f.latest(uuid) == nil {
+		return fakeAPIError(http.StatusNotFound)
+	}
+	delete(f.revisions, uuid)
+	return nil, nil
+}
+
+// fakeEdgeFunctionAPI is an in-memory EdgeFunctionAPI, keyed by shortname and name.
+type fakeEdgeFunctionAPI struct {
+	functions map[string]*edgefunctions.EdgeFunction
+	aliases   map[string]*edgefunctions.EdgeFunctionAlias
+	failures  fakeFailures
+	calls     []string
+}
+
+func newFakeEdgeFunctionAPI() *fakeEdgeFunctionAPI {
+	return &fakeEdgeFunctionAPI{
+		functions: map[string]*edgefunctions.EdgeFunction{},
+		aliases:   map[string]*edgefunctions.EdgeFunctionAlias{},
+		failures:  fakeFailures{},
+	}
+}
+
+func (f *fakeEdgeFunctionAPI) call(method string) (*http.Response, error) {
+	f.calls = append(f.calls, method)
+	return f.failures.fail(method)
+}
+
+func (f *fakeEdgeFunctionAPI) GetEdgeFunction(name string, shortname string) (*edgefunctions.EdgeFunction, *http.Response, error) {
+	if resp, err := f.call("GetEdgeFunction"); err != nil {
+		return nil, resp, err
+	}
+	edgeFunction, ok := f.functions[shortname+":"+name]
+	if !ok {
+		resp, err := fakeAPIError(http.StatusNotFound)
+		return nil, resp, err
+	}
+	copied := *edgeFunction
+	return &copied, nil, nil
+}
+
+func (f *fakeEdgeFunctionAPI) CreateEdgeFunction(shortname string, edgeFunction *edgefunctions.EdgeFunction, timeout time.Duration) (*edgefunctions.EdgeFunction, *http.Response, error) {
+	if resp, err := f.call("CreateEdgeFunction"); err != nil {
+		return nil, resp, err
+	}
+	created := *edgeFunction
+	created.Sha256 = testSha256(edgeFunction.FunctionArchive)
+	created.RevisionID = 1
+	f.functions[shortname+":"+edgeFunction.Name] = &created
+	return &created, nil, nil
+}
+
+func (f *fakeEdgeFunctionAPI) UpdateEdgeFunctionCode(name string, shortname string, functionArchive []byte, timeout time.Duration) (*edgefunctions.EdgeFunction, *http.Response, error) {
+	if resp, err := f.call("UpdateEdgeFunctionCode"); err != nil {
+		return nil, resp, err
+	}
+	edgeFunction, ok := f.functions[shortname+":"+name]
+	if !ok {
+		resp, err := fakeAPIError(http.StatusNotFound)
+		return nil, resp, err
+	}
+	edgeFunction.FunctionArchive = functionArchive
+	edgeFunction.Sha256 = testSha256(functionArchive)
+	edgeFunction.RevisionID++
+	return edgeFunction, nil, nil
+}
+
+func (f *fakeEdgeFunctionAPI) UpdateEdgeFunctionConfiguration(name string, shortname string, edgeFunction *edgefunctions.EdgeFunction, timeout time.Duration) (*edgefunctions.EdgeFunction, *http.Response, error) {
+	if resp, err := f.call("UpdateEdgeFunctionConfiguration"); err != nil {
+		return nil, resp, err
+	}
+	existing, ok := f.functions[shortname+":"+name]
+	if !ok {
+		resp, err := fakeAPIError(http.StatusNotFound)
+		return nil, resp, err
+	}
+	existing.Description = edgeFunction.Description
+	existing.Handler = edgeFunction.Handler
+	existing.Runtime = edgeFunction.Runtime
+	existing.Memory = edgeFunction.Memory
+	existing.Timeout = edgeFunction.Timeout
+	existing.CanDebug = edgeFunction.CanDebug
+	existing.EnvironmentVariables = edgeFunction.EnvironmentVariables
+	existing.RevisionID++
+	return existing, nil, nil
+}
+
+func (f *fakeEdgeFunctionAPI) SetEdgeFunctionConcurrency(name string, shortname string, concurrency int, timeout time.Duration) (*http.Response, error) {
+	if resp, err := f.call("SetEdgeFunctionConcurrency"); err != nil {
+		return resp, err
+	}
+	edgeFunction, ok := f.functions[shortname+":"+name]
+	if !ok {
+		return fakeAPIError(http.StatusNotFound)
+	}
+	edgeFunction.ReservedConcurrency = concurrency
+	return nil, nil
+}
+
+func (f *fakeEdgeFunctionAPI) DeleteEdgeFunction(name string, shortname string, timeout time.Duration) (*http.Response, error) {
+	if resp, err := f.call("DeleteEdgeFunction"); err != nil {
+		return resp, err
+	}
+	if _, ok := f.functions[shortname+":"+name]; !ok {
+		return fakeAPIError(http.StatusNotFound)
+	}
+	delete(f.functions, shortname+":"+name)
+	return nil, nil
+}
+
+func (f *fakeEdgeFunctionAPI) GetEdgeFunctionAlias(fnName string, shortname string, aliasName string) (*edgefunctions.EdgeFunctionAlias, *http.Response, error) {
+	if resp, err := f.call("GetEdgeFunctionAlias"); err != nil {
+		return nil, resp, err
+	}
+	alias, ok := f.aliases[shortname+":"+fnName+":"+aliasName]
+	if !ok {
+		resp, err := fakeAPIError(http.StatusNotFound)
+		return nil, resp, err
+	}
+	copied := *alias
+	return &copied, nil, nil
+}
+
+func (f *fakeEdgeFunctionAPI) CreateEdgeFunctionAlias(fnName string, shortname string, alias *edgefunctions.EdgeFunctionAlias, timeout time.Duration) (*edgefunctions.EdgeFunctionAlias, *http.Response, error) {
+	if resp, err := f.call("CreateEdgeFunctionAlias"); err != nil {
+		return nil, resp, err
+	}
+	if _, ok := f.functions[shortname+":"+fnName]; !ok {
+		resp, err := fakeAPIError(http.StatusNotFound)
+		return nil, resp, err
+	}
+	created := *alias
+	created.Function = fnName
+	created.RevisionID = 1
+	f.aliases[shortname+":"+fnName+":"+alias.Name] = &created
+	return &created, nil, nil
+}
+
+func (f *fakeEdgeFunctionAPI) UpdateEdgeFunctionAlias(fnName string, shortname string, aliasName string, alias *edgefunctions.EdgeFunctionAlias, timeout time.Duration) (*edgefunctions.EdgeFunctionAlias, *http.Response, error) {
+	if resp, err := f.call("UpdateEdgeFunctionAlias"); err != nil {
+		return nil, resp, err
+	}
+	existing, ok := f.aliases[shortname+":"+fnName+":"+aliasName]
+	if !ok {
+		resp, err := fakeAPIError(http.StatusNotFound)
+		return nil, resp, err
+	}
+	if alias.RevisionID != existing.RevisionID {
+		resp, err := fakeAPIError(http.StatusPreconditionFailed)
+		return nil, resp, err
+	}
+	existing.Description = alias.Description
+	existing.FunctionVersion = alias.FunctionVersion
+	existing.RevisionID++
+	return existing, nil, nil
+}
+
+func (f *fakeEdgeFunctionAPI) DeleteEdgeFunctionAlias(fnName string, shortname string, aliasName string, timeout time.Duration) (*http.Response, error) {
+	if resp, err := f.call("DeleteEdgeFunctionAlias"); err != nil {
+		return resp, err
+	}
+	if _, ok := f.aliases[shortname+":"+fnName+":"+aliasName]; !ok {
+		return fakeAPIError(http.StatusNotFound)
+	}
+	delete(f.aliases, shortname+":"+fnName+":"+aliasName)
+	return nil, nil
+}
+
+// fakeIPAllowListAPI is an IPAllowListAPI returning a fixed allow list.
+type fakeIPAllowListAPI struct {
+	ipList *configuration.IPAllowList
+}
+
+func (f *fakeIPAllowListAPI) GetIPAllowList() (*configuration.IPAllowList, *http.Response, error) {
+	return f.ipList, nil, nil
+}
+
+// newFakeMeta returns a provider configured with empty in-memory fakes.
+func newFakeMeta() *Meta {
+	return &Meta{
+		Deliveries:          newFakeDeliveryAPI(),
+		EdgeFunctions:       newFakeEdgeFunctionAPI(),
+		IPAllowList:         &fakeIPAllowListAPI{ipList: &configuration.IPAllowList{}},
+		OptionArgumentTypes: newOptionArgumentTypeCache(),
+	}
+}
+
+// resourceTestCase runs a CRUD function of a resource against the fakes in a Meta.
+type resourceTestCase struct {
+	name string
+	// setup adds existing objects to the fakes.
+	setup func(m *Meta)
+	// failures makes calls to the fakes fail during the operation, by method name.
+	failures fakeFailures
+	// operation is create, read, update or delete.
+	operation string
+	// id is the ID of the existing resource, read into the prior state of read, update and
+	// delete.
+	id string
+	// state overrides attributes of the prior state.
+	state map[string]string
+	// config is the configuration of create and update.
+	config        map[string]interface{}
+	expectedError string
+	check         func(t *testing.T, d *schema.ResourceData, m *Meta)
+}
+
+func runResourceTestCases(t *testing.T, r *schema.Resource, cases []resourceTestCase) {
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := newFakeMeta()
+			if c.setup != nil {
+				c.setup(m)
+			}
+
+			var state *terraform.InstanceState
+			if c.operation == "update" || c.operation == "delete" {
+				state = testResourceState(t, r, c.id, c.state, m)
+			}
+			setFakeFailures(m, c.failures)
+
+			var d *schema.ResourceData
+			var err error
+			switch c.operation {
+			case "create":
+				d = schema.TestResourceDataRaw(t, r.Schema, c.config)
+				err = r.Create(d, m)
+			case "read":
+				d = r.Data(nil)
+				d.SetId(c.id)
+				err = r.Read(d, m)
+			case "update":
+				diff, diffErr := r.Diff(state, terraform.NewResourceConfigRaw(c.config), m)
+				if diffErr != nil {
+					t.Fatalf("unexpected diff error: %s", diffErr)
+				}
+				if d, err = schema.InternalMap(r.Schema).Data(state, diff); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				err = r.Update(d, m)
+			case "delete":
+				d = r.Data(state)
+				err = r.Delete(d, m)
+			default:
+				t.Fatalf("unknown operation %s", c.operation)
+			}
+
+			if c.expectedError == "" && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if c.expectedError != "" && (err == nil || !strings.Contains(err.Error(), c.expectedError)) {
+				t.Fatalf("expected an error containing %q, got %v", c.expectedError, err)
+			}
+			if c.check != nil {
+				c.check(t, d, m)
+			}
+		})
+	}
+}
+
+// testResourceState reads an existing resource as terraform import would.
+func testResourceState(t *testing.T, r *schema.Resource, id string, attributes map[string]string, m *Meta) *terraform.InstanceState {
+	d := r.Data(nil)
+	d.SetId(id)
+	if err := r.Read(d, m); err != nil {
+		t.Fatalf("unexpected error reading the prior state: %s", err)
+	}
+
+	state := d.State()
+	if state == nil {
+		t.Fatalf("resource %s doesn't exist", id)
+	}
+	for k, v := range attributes {
+		state.Attributes[k] = v
+	}
+	return state
+}
+
+// setFakeFailures makes the calls to the given methods of the fakes in m fail, and resets
+// the calls recorded so far.
+func setFakeFailures(m *Meta, failures fakeFailures) {
+	if f, ok := m.Deliveries.(*fakeDeliveryAPI); ok {
+		f.failures = failures
+	}
+	if f, ok := m.EdgeFunctions.(*fakeEdgeFunctionAPI); ok {
+		f.failures = failures
+		f.calls = nil
+	}
+}
+
+func testSha256(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,152 @@
+package limelight
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// httpClientConfig is the provider's configuration of the connections to the APIs: the
+// proxy_url, ca_bundle_file, insecure_skip_verify, http_timeout and client_certificate
+// arguments.
+type httpClientConfig struct {
+	ProxyURL           string
+	CABundleFile       string
+	InsecureSkipVerify bool
+	Timeout            time.Duration
+	ClientCertFile     string
+	ClientKeyFile      string
+}
+
+// newHTTPTransport returns the transport API requests are sent with, which is
+// http.DefaultTransport unless the connections are configured. http.DefaultTransport
+// itself is never modified.
+func newHTTPTransport(config httpClientConfig) (http.RoundTripper, error) {
+	var transport http.RoundTripper = http.DefaultTransport
+
+	if config.ProxyURL != "" || config.CABundleFile != "" || config.InsecureSkipVerify || config.ClientCertFile != "" {
+		t, ok := http.DefaultTransport.(*http.Transport)
+		if ok {
+			t = t.Clone()
+		} else {
+			t = &http.Transport{Proxy: http.ProxyFromEnvironment}
+		}
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+
+		if config.ProxyURL != "" {
+			proxyURL, err := parseProxyURL(config.ProxyURL)
+			if err != nil {
+				return nil, err
+			}
+			t.Proxy = http.ProxyURL(proxyURL)
+		}
+
+		if config.CABundleFile != "" {
+			pool, err := loadCABundle(config.CABundleFile)
+			if err != nil {
+				return nil, err
+			}
+			t.TLSClientConfig.RootCAs = pool
+		}
+
+		if config.InsecureSkipVerify {
+			log.Printf("[WARN] TLS certificates of the APIs aren't verified, as insecure_skip_verify is set")
+			t.TLSClientConfig.InsecureSkipVerify = true
+		}
+
+		if config.ClientCertFile != "" {
+			certificate, err := tls.LoadX509KeyPair(config.ClientCertFile, config.ClientKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("error loading client_certificate: %s", err)
+			}
+			t.TLSClientConfig.Certificates = []tls.Certificate{certificate}
+		}
+
+		transport = t
+	}
+
+	if config.Timeout > 0 {
+		transport = &timeoutTransport{timeout: config.Timeout, transport: transport}
+	}
+
+	return transport, nil
+}
+
+func parseProxyURL(proxyURL string) (*url.URL, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy_url %s: %s", proxyURL, err)
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy_url %s: the scheme must be http, https or socks5", proxyURL)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy_url %s: no host", proxyURL)
+	}
+
+	return u, nil
+}
+
+// loadCABundle returns the system's certificate authorities, with those in the PEM file
+// at path added, e.g. the certificate of a TLS-intercepting proxy.
+func loadCABundle(path string) (*x509.CertPool, error) {
+	bundle, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading ca_bundle_file: %s", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		log.Printf("[WARN] Only trusting the certificate authorities in %s, as the system's can't be loaded: %v", path, err)
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(bundle) {
+		return nil, fmt.Errorf("no PEM certificates found in ca_bundle_file %s", path)
+	}
+
+	return pool, nil
+}
+
+// timeoutTransport fails requests that take longer than timeout, including reading the
+// response body. The API clients may give up on requests earlier.
+type timeoutTransport struct {
+	timeout   time.Duration
+	transport http.RoundTripper
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+
+	resp, err := t.transport.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,204 @@
+package limelight
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testWriteCertificate writes a new self-signed certificate and its key as PEM files in
+// dir.
+func testWriteCertificate(t *testing.T, dir string) (certFile string, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "terraform-test-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile = filepath.Join(dir, "client.crt")
+	keyFile = filepath.Join(dir, "client.key")
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func testGet(transport http.RoundTripper, url string) error {
+	resp, err := (&http.Client{Transport: transport}).Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = ioutil.ReadAll(resp.Body)
+	return err
+}
+
+func TestNewHTTPTransportDefault(t *testing.T) {
+	transport, err := newHTTPTransport(httpClientConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transport != http.DefaultTransport {
+		t.Errorf("expected http.DefaultTransport when nothing is configured, got %T", transport)
+	}
+}
+
+func TestNewHTTPTransportProxy(t *testing.T) {
+	var proxiedURL string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedURL = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	transport, err := newHTTPTransport(httpClientConfig{ProxyURL: proxy.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := testGet(transport, "http://apis.limelight.example/config-api/v1/"); err != nil {
+		t.Fatal(err)
+	}
+	if proxiedURL != "http://apis.limelight.example/config-api/v1/" {
+		t.Errorf("expected the request to go through the proxy, got %q", proxiedURL)
+	}
+}
+
+func TestNewHTTPTransportTLS(t *testing.T) {
+	var clientCertificates []string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, c := range r.TLS.PeerCertificates {
+			clientCertificates = append(clientCertificates, c.Subject.CommonName)
+		}
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "limelight-http-client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	caBundleFile := filepath.Join(dir, "ca.pem")
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := ioutil.WriteFile(caBundleFile, caBundle, 0600); err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile := testWriteCertificate(t, dir)
+
+	cases := []struct {
+		name                       string
+		config                     httpClientConfig
+		expectedError              string
+		expectedClientCertificates []string
+	}{
+		{"untrusted", httpClientConfig{Timeout: time.Second}, "certificate", nil},
+		{"ca bundle", httpClientConfig{CABundleFile: caBundleFile}, "", nil},
+		{"insecure", httpClientConfig{InsecureSkipVerify: true}, "", nil},
+		{"client certificate", httpClientConfig{CABundleFile: caBundleFile, ClientCertFile: certFile, ClientKeyFile: keyFile}, "", []string{"terraform-test-client"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			clientCertificates = nil
+			transport, err := newHTTPTransport(c.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = testGet(transport, server.URL)
+			if c.expectedError == "" && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+			if c.expectedError != "" && (err == nil || !strings.Contains(err.Error(), c.expectedError)) {
+				t.Errorf("expected an error containing %q, got %v", c.expectedError, err)
+			}
+			if strings.Join(clientCertificates, ",") != strings.Join(c.expectedClientCertificates, ",") {
+				t.Errorf("expected client certificates %v, got %v", c.expectedClientCertificates, clientCertificates)
+			}
+		})
+	}
+}
+
+func TestNewHTTPTransportTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	transport, err := newHTTPTransport(httpClientConfig{Timeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := testGet(transport, server.URL+"/fast"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := testGet(transport, server.URL+"/slow"); err == nil {
+		t.Error("expected the request to time out")
+	}
+}
+
+func TestNewHTTPTransportErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "limelight-http-client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	emptyBundle := filepath.Join(dir, "empty.pem")
+	if err := ioutil.WriteFile(emptyBundle, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name          string
+		config        httpClientConfig
+		expectedError string
+	}{
+		{"proxy scheme", httpClientConfig{ProxyURL: "ftp://proxy.example.com"}, "the scheme must be http, https or socks5"},
+		{"proxy host", httpClientConfig{ProxyURL: "http://"}, "no host"},
+		{"missing ca bundle", httpClientConfig{CABundleFile: filepath.Join(dir, "missing.pem")}, "error reading ca_bundle_file"},
+		{"empty ca bundle", httpClientConfig{CABundleFile: emptyBundle}, "no PEM certificates found"},
+		{"missing client certificate", httpClientConfig{ClientCertFile: "missing.crt", ClientKeyFile: "missing.key"}, "error loading client_certificate"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := newHTTPTransport(c.config)
+			if err == nil || !strings.Contains(err.Error(), c.expectedError) {
+				t.Errorf("expected an error containing %q, got %v", c.expectedError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package limelight
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/llnw/llnw-sdk-go/configuration"
+	"github.com/llnw/llnw-sdk-go/edgefunctions"
+)
+
+// Meta is the configured provider, passed to the CRUD functions of the resources and
+// data sources. The resources that are unit tested use the API through the narrow
+// interfaces below, so that they can be run against in-memory fakes.
+type Meta struct {
+	Deliveries    DeliveryAPI
+	EdgeFunctions EdgeFunctionAPI
+	IPAllowList   IPAllowListAPI
+
+	// The clients below are used directly by the other resources and data sources.
+	ConfigurationClient *configurationClient
+	EdgeFunctionsClient *edgeFunctionsClient
+	PurgeClient         *purgeClient
+	ReportingClient     *reportingClient
+
+	// Transport sends the requests of the clients, and downloads EdgeFunction archives.
+	Transport http.RoundTripper
+
+	OptionArgumentTypes   *optionArgumentTypeCache
+	DefaultShortname      string
+	DefaultServiceProfile string
+	ReadOnly              bool
+	Policy                *deliveryPolicy
+}
+
+// DeliveryAPI is the part of the Configuration API used by limelight_delivery and the
+// limelight_delivery_revisions data source.
+type DeliveryAPI interface {
+	GetConfigurationOptions(shortname string, profileName string) ([]configuration.ConfigOption, *http.Response, error)
+	GetDelivery(uuid string) (*serviceInstance, *http.Response, error)
+	GetDeliveryRevisions(uuid string) ([]serviceInstance, *http.Response, error)
+	CreateDelivery(body map[string]interface{}, shortname string, timeout time.Duration) (*serviceInstance, *http.Response, error)
+	UpdateDelivery(uuid string, body map[string]interface{}, shortname string, timeout time.Duration) (*serviceInstance, *http.Response, error)
+	DeleteDelivery(uuid string, timeout time.Duration) (*http.Response, error)
+}
+
+// EdgeFunctionAPI is the part of the EdgeFunctions API used by limelight_edgefunction and
+// limelight_edgefunction_alias.
+type EdgeFunctionAPI interface {
+	GetEdgeFunction(name string, shortname string) (*edgefunctions.EdgeFunction, *http.Response, error)
+	CreateEdgeFunction(shortname string, edgeFunction *edgefunctions.EdgeFunction, timeout time.Duration) (*edgefunctions.EdgeFunction, *http.Response, error)
+	UpdateEdgeFunctionCode(name string, shortname string, functionArchive []byte, timeout time.Duration) (*edgefunctions.EdgeFunction, *http.Response, error)
+	UpdateEdgeFunctionConfiguration(name string, shortname string, edgeFunction *edgefunctions.EdgeFunction, timeout time.Duration) (*edgefunctions.EdgeFunction, *http.Response, error)
+	SetEdgeFunctionConcurrency(name string, shortname string, concurrency int, timeout time.Duration) (*http.Response, error)
+	DeleteEdgeFunction(name string, shortname string, timeout time.Duration) (*http.Response, error)
+
+	GetEdgeFunctionAlias(fnName string, shortname string, aliasName string) (*edgefunctions.EdgeFunctionAlias, *http.Response, error)
+	CreateEdgeFunctionAlias(fnName string, shortname string, alias *edgefunctions.EdgeFunctionAlias, timeout time.Duration) (*edgefunctions.EdgeFunctionAlias, *http.Response, error)
+	UpdateEdgeFunctionAlias(fnName string, shortname string, aliasName string, alias *edgefunctions.EdgeFunctionAlias, timeout time.Duration) (*edgefunctions.EdgeFunctionAlias, *http.Response, error)
+	DeleteEdgeFunctionAlias(fnName string, shortname string, aliasName string, timeout time.Duration) (*http.Response, error)
+}
+
+// IPAllowListAPI is the part of the Configuration API used by the limelight_ip_ranges
+// data source.
+type IPAllowListAPI interface {
+	GetIPAllowList() (*configuration.IPAllowList, *http.Response, error)
+}
+
+// configurationAPI implements DeliveryAPI with the Configuration API client.
+type configurationAPI struct {
+	*configurationClient
+}
+
+func (c configurationAPI) GetDelivery(uuid string) (*serviceInstance, *http.Response, error) {
+	return getServiceInstance(c.configurationClient, deliveryServiceKey, uuid)
+}
+
+func (c configurationAPI) GetDeliveryRevisions(uuid string) ([]serviceInstance, *http.Response, error) {
+	return getServiceInstanceRevisions(c.configurationClient, deliveryServiceKey, uuid)
+}
+
+func (c configurationAPI) CreateDelivery(body map[string]interface{}, shortname string, timeout time.Duration) (*serviceInstance, *http.Response, error) {
+	return createServiceInstance(c.configurationClient, deliveryServiceKey, body, shortname, timeout)
+}
+
+func (c configurationAPI) UpdateDelivery(uuid string, body map[string]interface{}, shortname string, timeout time.Duration) (*serviceInstance, *http.Response, error) {
+	return updateServiceInstance(c.configurationClient, deliveryServiceKey, uuid, body, shortname, timeout)
+}
+
+func (c configurationAPI) DeleteDelivery(uuid string, timeout time.Duration) (*http.Response, error) {
+	return deleteServiceInstance(c.configurationClient, deliveryServiceKey, uuid, timeout)
+}
+
+// edgeFunctionsAPI implements EdgeFunctionAPI with the EdgeFunctions API client, whose
+// changes are bounded by the resources' timeouts.
+type edgeFunctionsAPI struct {
+	*edgeFunctionsClient
+}
+
+func (c edgeFunctionsAPI) CreateEdgeFunction(shortname string, edgeFunction *edgefunctions.EdgeFunction, timeout time.Duration) (*edgefunctions.EdgeFunction, *http.Response, error) {
+	return createEdgeFunction(c.edgeFunctionsClient, shortname, edgeFunction, timeout)
+}
+
+func (c edgeFunctionsAPI) UpdateEdgeFunctionCode(name string, shortname string, functionArchive []byte, timeout time.Duration) (*edgefunctions.EdgeFunction, *http.Response, error) {
+	return updateEdgeFunctionCode(c.edgeFunctionsClient, name, shortname, functionArchive, timeout)
+}
+
+func (c edgeFunctionsAPI) UpdateEdgeFunctionConfiguration(name string, shortname string, edgeFunction *edgefunctions.EdgeFunction, timeout time.Duration) (*edgefunctions.EdgeFunction, *http.Response, error) {
+	return updateEdgeFunctionConfiguration(c.edgeFunctionsClient, name, shortname, edgeFunction, timeout)
+}
+
+func (c edgeFunctionsAPI) SetEdgeFunctionConcurrency(name string, shortname string, concurrency int, timeout time.Duration) (*http.Response, error) {
+	return setEdgeFunctionConcurrency(c.edgeFunctionsClient, name, shortname, concurrency, timeout)
+}
+
+func (c edgeFunctionsAPI) DeleteEdgeFunction(name string, shortname string, timeout time.Duration) (*http.Response, error) {
+	return deleteEdgeFunction(c.edgeFunctionsClient, name, shortname, timeout)
+}
+
+func (c edgeFunctionsAPI) CreateEdgeFunctionAlias(fnName string, shortname string, alias *edgefunctions.EdgeFunctionAlias, timeout time.Duration) (*edgefunctions.EdgeFunctionAlias, *http.Response, error) {
+	return createEdgeFunctionAlias(c.edgeFunctionsClient, fnName, shortname, alias, timeout)
+}
+
+func (c edgeFunctionsAPI) UpdateEdgeFunctionAlias(fnName string, shortname string, aliasName string, alias *edgefunctions.EdgeFunctionAlias, timeout time.Duration) (*edgefunctions.EdgeFunctionAlias, *http.Response, error) {
+	return updateEdgeFunctionAlias(c.edgeFunctionsClient, fnName, shortname, aliasName, alias, timeout)
+}
+
+func (c edgeFunctionsAPI) DeleteEdgeFunctionAlias(fnName string, shortname string, aliasName string, timeout time.Duration) (*http.Response, error) {
+	return deleteEdgeFunctionAlias(c.edgeFunctionsClient, fnName, shortname, aliasName, timeout)
+}
+
+// getMeta returns the configured provider passed to a CRUD function.
+func getMeta(m interface{}) *Meta {
+	return m.(*Meta)
+}
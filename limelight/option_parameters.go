@@ -0,0 +1,106 @@
+package limelight
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// optionArgumentTypeCache caches the argument types of the configuration options
+// available to each shortname and service profile.
+type optionArgumentTypeCache struct {
+	lock  sync.Mutex
+	types map[string]map[string][]string
+}
+
+func newOptionArgumentTypeCache() *optionArgumentTypeCache {
+	return &optionArgumentTypeCache{types: map[string]map[string][]string{}}
+}
+
+// get returns the argument types of each option, keyed by option name.
+func (cache *optionArgumentTypeCache) get(c DeliveryAPI, shortname string, serviceProfile string) (map[string][]string, error) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	key := shortname + "/" + serviceProfile
+	if types, ok := cache.types[key]; ok {
+		return types, nil
+	}
+
+	log.Printf("[INFO] Fetching configuration options for service profile: %s", serviceProfile)
+	configOptions, _, err := c.GetConfigurationOptions(shortname, serviceProfile)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching configuration options: %s", err)
+	}
+
+	types := make(map[string][]string, len(configOptions))
+	for _, option := range configOptions {
+		argumentTypes := make([]string, len(option.Body.Details.Arguments))
+		for i, argument := range option.Body.Details.Arguments {
+			argumentTypes[i] = argument.Type
+		}
+		types[option.Body.Name] = argumentTypes
+	}
+	cache.types[key] = types
+
+	return types, nil
+}
+
+// expandOptionParameter converts a parameter from its string form in the configuration
+// to the JSON value expected by the API for an argument of type argumentType. Values
+// that aren't valid for the type, and arguments of unknown type, are sent as strings.
+func expandOptionParameter(value string, argumentType string) interface{} {
+	switch strings.ToLower(argumentType) {
+	case "int", "integer", "long":
+		if intVal, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return int(intVal)
+		}
+	case "float", "double", "decimal", "number":
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil && !math.IsInf(floatVal, 0) && !math.IsNaN(floatVal) {
+			return floatVal
+		}
+	case "bool", "boolean":
+		if boolVal, err := strconv.ParseBool(value); err == nil {
+			return boolVal
+		}
+	case "list", "array":
+		var listVal []interface{}
+		if err := json.Unmarshal([]byte(value), &listVal); err == nil && listVal != nil {
+			return listVal
+		}
+	case "map", "object", "json":
+		var objectVal map[string]interface{}
+		if err := json.Unmarshal([]byte(value), &objectVal); err == nil && objectVal != nil {
+			return objectVal
+		}
+	}
+
+	return value
+}
+
+// flattenOptionParameter converts a parameter returned by the API to its string form in
+// the configuration, without losing precision. Lists and objects are encoded as JSON.
+func flattenOptionParameter(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}, map[string]interface{}:
+		if encoded, err := json.Marshal(v); err == nil {
+			return string(encoded)
+		}
+	}
+
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,112 @@
+package limelight
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestExpandOptionParameter(t *testing.T) {
+	cases := []struct {
+		value        string
+		argumentType string
+		expected     interface{}
+	}{
+		{"3600", "Int", 3600},
+		{"-1", "Integer", -1},
+		{"abc", "Int", "abc"},
+		{"0.5", "Int", "0.5"},
+		{"0.5", "Float", 0.5},
+		{"30", "Double", float64(30)},
+		{"NaN", "Float", "NaN"},
+		{"true", "Boolean", true},
+		{"false", "Bool", false},
+		{"yes", "Boolean", "yes"},
+		{`["a", 1]`, "List", []interface{}{"a", float64(1)}},
+		{`{"a": 1}`, "List", `{"a": 1}`},
+		{`{"a": [true]}`, "Object", map[string]interface{}{"a": []interface{}{true}}},
+		{"null", "Object", "null"},
+		{"X-LLNW-Test", "String", "X-LLNW-Test"},
+		{"200", "", "200"},
+	}
+
+	for _, tc := range cases {
+		actual := expandOptionParameter(tc.value, tc.argumentType)
+		if !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("expandOptionParameter(%q, %q): expected %#v, got %#v", tc.value, tc.argumentType, tc.expected, actual)
+		}
+	}
+}
+
+func TestFlattenOptionParameter(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		expected string
+	}{
+		{"X-LLNW-Test", "X-LLNW-Test"},
+		{float64(3600), "3600"},
+		{0.5, "0.5"},
+		{1e21, "1000000000000000000000"},
+		{-0.125, "-0.125"},
+		{true, "true"},
+		{false, "false"},
+		{nil, ""},
+		{[]interface{}{"a", float64(1)}, `["a",1]`},
+		{map[string]interface{}{"b": 2.5, "a": []interface{}{}}, `{"a":[],"b":2.5}`},
+	}
+
+	for _, tc := range cases {
+		if actual := flattenOptionParameter(tc.value); actual != tc.expected {
+			t.Errorf("flattenOptionParameter(%#v): expected %q, got %q", tc.value, tc.expected, actual)
+		}
+	}
+}
+
+func TestOptionParameterRoundTrip(t *testing.T) {
+	cases := []struct {
+		argumentType string
+		apiValue     string
+	}{
+		{"Int", `3600`},
+		{"Float", `0.5`},
+		{"Float", `123456.789`},
+		{"Boolean", `true`},
+		{"String", `"X-LLNW-Test"`},
+		{"List", `["a",1,[true]]`},
+		{"Object", `{"a":{"b":[1.5]}}`},
+	}
+
+	for _, tc := range cases {
+		var apiValue interface{}
+		if err := json.Unmarshal([]byte(tc.apiValue), &apiValue); err != nil {
+			t.Fatal(err)
+		}
+
+		expanded := expandOptionParameter(flattenOptionParameter(apiValue), tc.argumentType)
+
+		encoded, err := json.Marshal(expanded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(encoded) != tc.apiValue {
+			t.Errorf("expected %s to round trip as type %s, got %s", tc.apiValue, tc.argumentType, encoded)
+		}
+	}
+}
+
+func TestOptionParameterStable(t *testing.T) {
+	values := []string{"3600", "0.5", "-1e-7", "1e21", "007", "true", "X-LLNW-Test", `["a",1]`, `{"a":null}`, "null", " 1", ""}
+	argumentTypes := []string{"Int", "Float", "Boolean", "String", "List", "Object", ""}
+
+	for _, value := range values {
+		for _, argumentType := range argumentTypes {
+			expanded := expandOptionParameter(value, argumentType)
+			flattened := flattenOptionParameter(expanded)
+
+			if reexpanded := expandOptionParameter(flattened, argumentType); !reflect.DeepEqual(reexpanded, expanded) {
+				t.Errorf("%q as %q expanded to %#v, but its flattened form %q expanded to %#v",
+					value, argumentType, expanded, flattened, reexpanded)
+			}
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package limelight
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/llnw/llnw-sdk-go/configuration"
+)
+
+// deliveryPolicy holds the rules of the provider's policy block, which every delivery
+// configuration has to follow, whether it is a limelight_delivery or a
+// limelight_service_instance of the delivery service key. The rules are checked when
+// planning, and again on the request body before it is sent, as values that are unknown
+// when planning can only be checked then.
+type deliveryPolicy struct {
+	AllowedServiceProfiles           []string
+	AllowedSourceHostnamePatterns    []string
+	AllowedPublishedHostnamePatterns []string
+	RequireHTTPSSource               bool
+	RequireHTTPSPublished            bool
+	ForbiddenOptions                 []string
+}
+
+// deliveryPolicyKeys are the arguments whose changes are checked against the
+// policy. Deliveries that aren't changed aren't checked, so that adopting a policy
+// doesn't fail every plan until all existing deliveries comply.
+var deliveryPolicyKeys = []string{
+	"service_profile",
+	"protocol_set",
+	"published_hostname",
+	"source_hostname",
+	"body_json",
+}
+
+func deliveryPolicySchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "Rules that every delivery configuration has to follow, enforced when planning",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"allowed_service_profiles": &schema.Schema{
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+				},
+				"allowed_source_hostname_patterns": &schema.Schema{
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem: &schema.Schema{
+						Type:         schema.TypeString,
+						ValidateFunc: validateHostnamePattern,
+					},
+				},
+				"allowed_published_hostname_patterns": &schema.Schema{
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem: &schema.Schema{
+						Type:         schema.TypeString,
+						ValidateFunc: validateHostnamePattern,
+					},
+				},
+				"require_https_source": &schema.Schema{
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  false,
+				},
+				"require_https_published": &schema.Schema{
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  false,
+				},
+				"forbidden_options": &schema.Schema{
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+				},
+			},
+		},
+	}
+}
+
+func validateHostnamePattern(v interface{}, k string) (ws []string, errors []error) {
+	if _, err := path.Match(v.(string), ""); err != nil {
+		errors = append(errors, fmt.Errorf("%q is not a valid hostname pattern: %s", v, err))
+	}
+	return
+}
+
+// expandDeliveryPolicy returns the policy configured by the policy block, or nil when it
+// isn't set.
+func expandDeliveryPolicy(flattenedPolicy []interface{}) *deliveryPolicy {
+	if len(flattenedPolicy) == 0 || flattenedPolicy[0] == nil {
+		return nil
+	}
+
+	rawPolicy := flattenedPolicy[0].(map[string]interface{})
+	return &deliveryPolicy{
+		AllowedServiceProfiles:           expandStringList(rawPolicy["allowed_service_profiles"].([]interface{})),
+		AllowedSourceHostnamePatterns:    expandStringList(rawPolicy["allowed_source_hostname_patterns"].([]interface{})),
+		AllowedPublishedHostnamePatterns: expandStringList(rawPolicy["allowed_published_hostname_patterns"].([]interface{})),
+		RequireHTTPSSource:               rawPolicy["require_https_source"].(bool),
+		RequireHTTPSPublished:            rawPolicy["require_https_published"].(bool),
+		ForbiddenOptions:                 expandStringList(rawPolicy["forbidden_options"].([]interface{})),
+	}
+}
+
+// customizeDiffDeliveryPolicy fails the plan of a delivery that is created or changed in
+// a way that violates the provider's policy.
+func customizeDiffDeliveryPolicy(d *schema.ResourceDiff, m interface{}) error {
+	meta, ok := m.(*Meta)
+	if !ok || meta.Policy == nil {
+		return nil
+	}
+
+	if d.Id() != "" {
+		changed := false
+		for _, k := range deliveryPolicyKeys {
+			changed = changed || d.HasChange(k)
+		}
+		if !changed {
+			return nil
+		}
+	}
+
+	// The option parameters don't matter to the policy, so they are left as strings
+	// rather than looking up the types of the option arguments.
+	body, err := deliveryRequestBody(d, resolveServiceProfile(d, m), nil)
+	if err != nil {
+		return err
+	}
+
+	return checkDeliveryPolicy(meta.Policy, body)
+}
+
+// customizeDiffServiceInstancePolicy fails the plan of a delivery service instance that
+// is created or changed in a way that violates the provider's policy, as it is the same
+// delivery configuration as a limelight_delivery.
+func customizeDiffServiceInstancePolicy(d *schema.ResourceDiff, m interface{}) error {
+	meta, ok := m.(*Meta)
+	if !ok || meta.Policy == nil || d.Get("service_key").(string) != deliveryServiceKey {
+		return nil
+	}
+
+	if (d.Id() != "" && !d.HasChange("body_json")) || !d.NewValueKnown("body_json") {
+		return nil
+	}
+
+	body, err := expandServiceInstanceBody(d.Get("body_json").(string), deliveryServiceKey)
+	if err != nil {
+		return err
+	}
+
+	return checkDeliveryPolicy(meta.Policy, body)
+}
+
+// checkDeliveryPolicy checks a delivery service instance body, with body_json merged into
+// it, against the policy. Empty values are values that are unknown when planning, and
+// aren't checked.
+func checkDeliveryPolicy(policy *deliveryPolicy, body map[string]interface{}) error {
+	if policy == nil {
+		return nil
+	}
+
+	delivery := &configuration.DeliveryServiceInstanceBody{}
+	if err := (&serviceInstance{Body: body}).decodeBody(delivery); err != nil {
+		return fmt.Errorf("error checking delivery configuration against the policy: %s", err)
+	}
+
+	var violations []string
+	violation := func(rule string, format string, a ...interface{}) {
+		violations = append(violations, fmt.Sprintf("%s: %s", rule, fmt.Sprintf(format, a...)))
+	}
+
+	if len(policy.AllowedServiceProfiles) > 0 && delivery.ServiceProfileName != "" &&
+		!stringInList(delivery.ServiceProfileName, policy.AllowedServiceProfiles) {
+		violation("allowed_service_profiles", "service profile %q is not allowed", delivery.ServiceProfileName)
+	}
+
+	if len(policy.AllowedSourceHostnamePatterns) > 0 && delivery.SourceHostname != "" &&
+		!hostnameMatchesAny(delivery.SourceHostname, policy.AllowedSourceHostnamePatterns) {
+		violation("allowed_source_hostname_patterns", "source hostname %q is not allowed", delivery.SourceHostname)
+	}
+
+	if len(policy.AllowedPublishedHostnamePatterns) > 0 && delivery.PublishedHostname != "" &&
+		!hostnameMatchesAny(delivery.PublishedHostname, policy.AllowedPublishedHostnamePatterns) {
+		violation("allowed_published_hostname_patterns", "published hostname %q is not allowed", delivery.PublishedHostname)
+	}
+
+	for _, protocolSet := range delivery.ProtocolSets {
+		if policy.RequireHTTPSSource && protocolSet.SourceProtocol != "" && protocolSet.SourceProtocol != "https" {
+			violation("require_https_source", "source protocol %q is not allowed, only https", protocolSet.SourceProtocol)
+		}
+		if policy.RequireHTTPSPublished && protocolSet.PublishedProtocol != "" && protocolSet.PublishedProtocol != "https" {
+			violation("require_https_published", "published protocol %q is not allowed, only https", protocolSet.PublishedProtocol)
+		}
+		for _, option := range protocolSet.Options {
+			if stringInList(option.Name, policy.ForbiddenOptions) {
+				violation("forbidden_options", "option %q is not allowed", option.Name)
+			}
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return fmt.Errorf("delivery configuration violates the provider policy:\n  %s", strings.Join(violations, "\n  "))
+}
+
+// hostnameMatchesAny returns whether the hostname matches any of the patterns, in which
+// * matches any sequence of characters. Hostnames are matched case-insensitively.
+func hostnameMatchesAny(hostname string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(hostname)); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,264 @@
+package limelight
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+// testUnknownValue is how the SDK represents a value that is unknown when planning.
+const testUnknownValue = "74D93920-ED26-11E3-AC10-0800200C9A66"
+
+func testPolicyDeliveryConfig(changes map[string]interface{}) map[string]interface{} {
+	config := testDeliveryConfig("/")
+	for k, v := range changes {
+		config[k] = v
+	}
+	return config
+}
+
+func TestCustomizeDiffDeliveryPolicy(t *testing.T) {
+	r := resourceLimelightDelivery()
+	policy := &deliveryPolicy{
+		AllowedServiceProfiles:           []string{defaultServiceProfile},
+		AllowedSourceHostnamePatterns:    []string{"*.example.com"},
+		AllowedPublishedHostnamePatterns: []string{"www.example.com", "*.cdn.example.com"},
+		RequireHTTPSSource:               true,
+		ForbiddenOptions:                 []string{"reply_send_header"},
+	}
+
+	cases := []struct {
+		name          string
+		policy        *deliveryPolicy
+		existing      bool
+		config        map[string]interface{}
+		expectedError string
+	}{
+		{
+			name:   "compliant",
+			policy: policy,
+			config: testPolicyDeliveryConfig(map[string]interface{}{"published_hostname": "Images.CDN.example.com"}),
+		},
+		{
+			name:   "no policy",
+			config: testPolicyDeliveryConfig(map[string]interface{}{"source_hostname": "origin.example.net"}),
+		},
+		{
+			name:          "service profile",
+			policy:        policy,
+			config:        testPolicyDeliveryConfig(map[string]interface{}{"service_profile": "LLNW-Custom"}),
+			expectedError: `allowed_service_profiles: service profile "LLNW-Custom" is not allowed`,
+		},
+		{
+			name:          "source hostname",
+			policy:        policy,
+			config:        testPolicyDeliveryConfig(map[string]interface{}{"source_hostname": "origin.example.net"}),
+			expectedError: `allowed_source_hostname_patterns: source hostname "origin.example.net" is not allowed`,
+		},
+		{
+			name:          "published hostname",
+			policy:        policy,
+			config:        testPolicyDeliveryConfig(map[string]interface{}{"published_hostname": "example.com"}),
+			expectedError: `allowed_published_hostname_patterns: published hostname "example.com" is not allowed`,
+		},
+		{
+			name:   "source protocol",
+			policy: policy,
+			config: testPolicyDeliveryConfig(map[string]interface{}{
+				"protocol_set": []interface{}{
+					map[string]interface{}{"published_protocol": "https", "source_protocol": "http"},
+				},
+			}),
+			expectedError: `require_https_source: source protocol "http" is not allowed`,
+		},
+		{
+			name:   "forbidden option",
+			policy: policy,
+			config: testPolicyDeliveryConfig(map[string]interface{}{
+				"protocol_set": []interface{}{
+					map[string]interface{}{
+						"published_protocol": "https",
+						"source_protocol":    "https",
+						"option": []interface{}{
+							map[string]interface{}{"name": "reply_send_header", "parameters": []interface{}{"X-Test", "1"}},
+						},
+					},
+				},
+			}),
+			expectedError: `forbidden_options: option "reply_send_header" is not allowed`,
+		},
+		{
+			name:          "body_json",
+			policy:        policy,
+			config:        testPolicyDeliveryConfig(map[string]interface{}{"body_json": `{"sourceHostname": "origin.example.net"}`}),
+			expectedError: "allowed_source_hostname_patterns",
+		},
+		{
+			name:   "unknown values",
+			policy: policy,
+			config: testPolicyDeliveryConfig(map[string]interface{}{
+				"source_hostname": testUnknownValue,
+				"body_json":       testUnknownValue,
+			}),
+		},
+		{
+			name:     "existing unchanged",
+			policy:   &deliveryPolicy{RequireHTTPSPublished: true, AllowedServiceProfiles: []string{"LLNW-Custom"}},
+			existing: true,
+			config:   testPolicyDeliveryConfig(map[string]interface{}{"deletion_protection": true}),
+		},
+		{
+			name:          "existing changed",
+			policy:        &deliveryPolicy{AllowedServiceProfiles: []string{"LLNW-Custom"}},
+			existing:      true,
+			config:        testPolicyDeliveryConfig(map[string]interface{}{"source_hostname": "origin2.example.com"}),
+			expectedError: "allowed_service_profiles",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var state *terraform.InstanceState
+			if c.existing {
+				state = &terraform.InstanceState{
+					ID: "uuid-1",
+					Attributes: map[string]string{
+						"id":                                "uuid-1",
+						"shortname":                         "example",
+						"service_profile":                   defaultServiceProfile,
+						"published_hostname":                "www.example.com",
+						"published_path":                    "/",
+						"source_hostname":                   "origin.example.com",
+						"source_path":                       "/",
+						"protocol_set.#":                    "1",
+						"protocol_set.0.published_protocol": "https",
+						"protocol_set.0.source_protocol":    "https",
+						"protocol_set.0.source_port":        "0",
+						"protocol_set.0.option.#":           "0",
+						"deletion_protection":               "false",
+						"purge_on_change":                   "false",
+					},
+				}
+			}
+
+			_, err := r.Diff(state, terraform.NewResourceConfigRaw(c.config), &Meta{Policy: c.policy})
+			if c.expectedError == "" && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+			if c.expectedError != "" && (err == nil || !strings.Contains(err.Error(), c.expectedError)) {
+				t.Errorf("expected an error containing %q, got %v", c.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestCustomizeDiffServiceInstancePolicy(t *testing.T) {
+	r := resourceLimelightServiceInstance()
+	policy := &deliveryPolicy{AllowedSourceHostnamePatterns: []string{"*.example.com"}}
+	violatingBody := `{"sourceHostname": "origin.example.net"}`
+
+	cases := []struct {
+		name          string
+		serviceKey    string
+		existing      bool
+		bodyJSON      string
+		expectedError string
+	}{
+		{name: "compliant", serviceKey: deliveryServiceKey, bodyJSON: `{"sourceHostname": "origin.example.com"}`},
+		{name: "violating", serviceKey: deliveryServiceKey, bodyJSON: violatingBody, expectedError: "allowed_source_hostname_patterns"},
+		{name: "other service key", serviceKey: "webrtc", bodyJSON: violatingBody},
+		{name: "unknown body", serviceKey: deliveryServiceKey, bodyJSON: testUnknownValue},
+		{name: "existing unchanged", serviceKey: deliveryServiceKey, existing: true, bodyJSON: violatingBody},
+		{name: "existing changed", serviceKey: deliveryServiceKey, existing: true, bodyJSON: `{"sourceHostname": "origin2.example.net"}`, expectedError: "allowed_source_hostname_patterns"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var state *terraform.InstanceState
+			if c.existing {
+				state = &terraform.InstanceState{
+					ID: c.serviceKey + ":uuid-1",
+					Attributes: map[string]string{
+						"id":          c.serviceKey + ":uuid-1",
+						"shortname":   "example",
+						"service_key": c.serviceKey,
+						"body_json":   violatingBody,
+					},
+				}
+			}
+
+			config := map[string]interface{}{
+				"shortname":   "example",
+				"service_key": c.serviceKey,
+				"body_json":   c.bodyJSON,
+			}
+			_, err := r.Diff(state, terraform.NewResourceConfigRaw(config), &Meta{Policy: policy})
+			if c.expectedError == "" && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+			if c.expectedError != "" && (err == nil || !strings.Contains(err.Error(), c.expectedError)) {
+				t.Errorf("expected an error containing %q, got %v", c.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestServiceInstanceCreateChecksDeliveryPolicy(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	r := resourceLimelightServiceInstance()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"shortname":   "example",
+		"service_key": deliveryServiceKey,
+		"body_json":   `{"sourceHostname": "origin.example.net"}`,
+	})
+	m := &Meta{
+		ConfigurationClient: newConfigurationClient("user", "00", server.URL),
+		Policy:              &deliveryPolicy{AllowedSourceHostnamePatterns: []string{"*.example.com"}},
+	}
+
+	err := r.Create(d, m)
+	if err == nil || !strings.Contains(err.Error(), "allowed_source_hostname_patterns") {
+		t.Errorf("expected a policy violation, got %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("expected no requests, got %d", requests)
+	}
+}
+
+func TestCheckDeliveryPolicyViolations(t *testing.T) {
+	policy := &deliveryPolicy{RequireHTTPSSource: true, RequireHTTPSPublished: true}
+	body := map[string]interface{}{
+		"protocolSets": []interface{}{
+			map[string]interface{}{"publishedProtocol": "http", "sourceProtocol": "http"},
+		},
+	}
+
+	err := checkDeliveryPolicy(policy, body)
+	if err == nil {
+		t.Fatal("expected a policy violation")
+	}
+	for _, rule := range []string{"require_https_source", "require_https_published"} {
+		if !strings.Contains(err.Error(), rule) {
+			t.Errorf("expected the error to name %s, got %s", rule, err)
+		}
+	}
+}
+
+func TestValidateHostnamePattern(t *testing.T) {
+	if _, errs := validateHostnamePattern("*.example.com", "pattern"); len(errs) != 0 {
+		t.Errorf("unexpected errors %v", errs)
+	}
+	if _, errs := validateHostnamePattern("[example.com", "pattern"); len(errs) == 0 {
+		t.Error("expected an invalid pattern error")
+	}
+}
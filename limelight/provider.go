@@ -2,9 +2,9 @@ package limelight
 
 import (
 	"fmt"
+	"log"
+	"time"
 
-	"github.com/llnw/llnw-sdk-go/configuration"
-	"github.com/llnw/llnw-sdk-go/edgefunctions"
 	"github.com/llnw/terraform-provider-limelight/version"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
@@ -30,34 +30,131 @@ func Provider() terraform.ResourceProvider {
 				DefaultFunc: schema.EnvDefaultFunc("LLNW_EDGEFUNCTIONS_API_URL", nil),
 				Description: "The base URL for the Limelight Networks EdgeFunctions API (trailing / should be omitted)",
 			},
+			"purge_api_base_url": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("LLNW_PURGE_API_URL", nil),
+				Description: "The base URL for the Limelight Networks Purge API (trailing / should be omitted)",
+			},
+			"reporting_api_base_url": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("LLNW_REPORTING_API_URL", nil),
+				Description: "The base URL for the Limelight Networks Reporting API (trailing / should be omitted)",
+			},
 			"username": {
 				Type:         schema.TypeString,
-				Required:     true,
+				Optional:     true,
 				DefaultFunc:  schema.EnvDefaultFunc("LLNW_API_USERNAME", nil),
 				Description:  "The username to be used for authenticating with the Limelight Networks Configuration API",
 				ValidateFunc: validation.NoZeroValues,
 			},
 			"api_key": {
 				Type:         schema.TypeString,
-				Required:     true,
+				Optional:     true,
 				Sensitive:    true,
 				DefaultFunc:  schema.EnvDefaultFunc("LLNW_API_KEY", nil),
 				Description:  "The API key to be used for authenticating with the Limelight Networks Configuration API",
 				ValidateFunc: validation.NoZeroValues,
 			},
+			"credential_process": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("LLNW_CREDENTIAL_PROCESS", nil),
+				Description:  "A command that prints JSON credentials ({username, api_key, expiration}) to stdout, used instead of username and api_key",
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"default_shortname": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("LLNW_DEFAULT_SHORTNAME", nil),
+				Description: "The shortname used by resources that don't set one",
+			},
+			"default_service_profile": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("LLNW_DEFAULT_SERVICE_PROFILE", nil),
+				Description: "The service profile used by delivery configurations that don't set one",
+			},
+			"read_only": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("LLNW_READ_ONLY", false),
+				Description: "Fail any change to resources, and reject any API request that isn't a read",
+			},
+			"proxy_url": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("LLNW_PROXY_URL", nil),
+				Description: "The URL of the proxy to send API requests through, instead of the one set by HTTPS_PROXY",
+			},
+			"ca_bundle_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("LLNW_CA_BUNDLE_FILE", nil),
+				Description: "A PEM file of certificate authorities to trust in addition to the system's",
+			},
+			"insecure_skip_verify": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("LLNW_INSECURE_SKIP_VERIFY", false),
+				Description: "Don't verify the TLS certificates of the APIs, only for local stand-ins of the APIs",
+			},
+			"http_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("LLNW_HTTP_TIMEOUT", nil),
+				Description:  "The longest an API request may take, e.g. \"20s\"",
+				ValidateFunc: validatePositiveDuration,
+			},
+			"client_certificate": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "A TLS client certificate to present to the APIs or the proxy",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"cert_file": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"key_file": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+					},
+				},
+			},
+			"skip_credentials_validation": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("LLNW_SKIP_CREDENTIALS_VALIDATION", false),
+				Description: "Don't validate the credentials with the APIs when the provider is configured",
+			},
+			"policy": deliveryPolicySchema(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"limelight_ip_ranges": dataSourceLimelightIPRanges(),
+			"limelight_delivery_revisions":   dataSourceLimelightDeliveryRevisions(),
+			"limelight_edgefunction_logs":    dataSourceLimelightEdgeFunctionLogs(),
+			"limelight_edgefunction_metrics": dataSourceLimelightEdgeFunctionMetrics(),
+			"limelight_ip_ranges":            dataSourceLimelightIPRanges(),
+			"limelight_usage_report":         dataSourceLimelightUsageReport(),
 		},
 		ResourcesMap: map[string]*schema.Resource{
 			"limelight_delivery":           resourceLimelightDelivery(),
 			"limelight_edgefunction":       resourceLimelightEdgeFunction(),
 			"limelight_edgefunction_alias": resourceLimelightEdgeFunctionAlias(),
+			"limelight_service_instance":   resourceLimelightServiceInstance(),
+			"limelight_purge":              resourceLimelightPurge(),
 			// TODO: enable in RTS v2
 			//"limelight_realtime_streaming_slot": resourceLimelightRealtimeStreamingSlot(),
 		},
 	}
 
+	for resourceType, r := range provider.ResourcesMap {
+		guardReadOnlyResource(resourceType, r)
+	}
+
 	provider.ConfigureFunc = func(d *schema.ResourceData) (interface{}, error) {
 		terraformVersion := provider.TerraformVersion
 		if terraformVersion == "" {
@@ -74,44 +171,194 @@ func Provider() terraform.ResourceProvider {
 func providerConfigure(d *schema.ResourceData, terraformVersion string) (interface{}, error) {
 	configBaseURL := d.Get("config_api_base_url").(string)
 	edgefunctionsBaseURL := d.Get("edgefunctions_api_base_url").(string)
+	purgeBaseURL := d.Get("purge_api_base_url").(string)
+	reportingBaseURL := d.Get("reporting_api_base_url").(string)
 	username := d.Get("username").(string)
 	apiKey := d.Get("api_key").(string)
+	credentialProcessCommand := d.Get("credential_process").(string)
 
-	m := make(map[string]interface{})
-
-	var configurationClient *configuration.ConfigurationClient
-	var edgeFunctionsClient *edgefunctions.EdgeFunctionsClient
-
-	if len(configBaseURL) == 0 {
-		configurationClient = configuration.NewClient(username, apiKey)
-	} else {
-		configurationClient = configuration.NewClientOverrideBaseUrl(username, apiKey, configBaseURL)
+	var credentials *credentialProcess
+	if len(credentialProcessCommand) > 0 {
+		credentials = newCredentialProcess(credentialProcessCommand)
+		var err error
+		username, apiKey, err = credentials.credentials()
+		if err != nil {
+			return nil, err
+		}
+	} else if len(username) == 0 || len(apiKey) == 0 {
+		return nil, fmt.Errorf("username and api_key must be set unless credential_process is configured")
 	}
 
-	if len(edgefunctionsBaseURL) == 0 {
-		edgeFunctionsClient = edgefunctions.NewClient(username, apiKey)
-	} else {
-		edgeFunctionsClient = edgefunctions.NewClientOverrideBaseUrl(username, apiKey, edgefunctionsBaseURL)
-	}
+	configurationClient := newConfigurationClient(username, apiKey, configBaseURL)
+	edgeFunctionsClient := newEdgeFunctionsClient(username, apiKey, edgefunctionsBaseURL)
+	purgeAPIClient := newPurgeClient(username, apiKey, purgeBaseURL)
+	reportingAPIClient := newReportingClient(username, apiKey, reportingBaseURL)
 
 	terraformUserAgent := httpclient.TerraformUserAgent(terraformVersion)
 	providerUserAgent := fmt.Sprintf("terraform-provider-limelight/%s", version.ProviderVersion)
 	userAgent := fmt.Sprintf("%s %s", terraformUserAgent, providerUserAgent)
 	configurationClient.SetUserAgent(userAgent)
 	edgeFunctionsClient.SetUserAgent(userAgent)
+	purgeAPIClient.SetUserAgent(userAgent)
+	reportingAPIClient.SetUserAgent(userAgent)
+
+	readOnly := d.Get("read_only").(bool)
+
+	httpClientConfig := expandHTTPClientConfig(d)
+	transport, err := newHTTPTransport(httpClientConfig)
+	if err != nil {
+		return nil, err
+	}
+	if readOnly {
+		transport = &readOnlyTransport{transport: transport}
+	}
+	transport = &loggingTransport{transport: transport}
+	if credentials != nil {
+		credentials.register(configurationClient.Auth)
+		credentials.register(edgeFunctionsClient.Auth)
+		credentials.register(purgeAPIClient.Auth)
+		credentials.register(reportingAPIClient.Auth)
+		transport = &credentialProcessTransport{process: credentials, transport: transport}
+	}
+	for _, client := range []*apiClient{configurationClient.apiClient, edgeFunctionsClient.apiClient, purgeAPIClient.apiClient, reportingAPIClient.apiClient} {
+		client.transport = transport
+		if httpClientConfig.Timeout > 0 {
+			client.readTimeout = httpClientConfig.Timeout
+		}
+	}
+
+	meta := &Meta{
+		Deliveries:            configurationAPI{configurationClient},
+		EdgeFunctions:         edgeFunctionsAPI{edgeFunctionsClient},
+		IPAllowList:           configurationClient,
+		ConfigurationClient:   configurationClient,
+		EdgeFunctionsClient:   edgeFunctionsClient,
+		PurgeClient:           purgeAPIClient,
+		ReportingClient:       reportingAPIClient,
+		Transport:             transport,
+		OptionArgumentTypes:   newOptionArgumentTypeCache(),
+		DefaultShortname:      d.Get("default_shortname").(string),
+		DefaultServiceProfile: d.Get("default_service_profile").(string),
+		ReadOnly:              readOnly,
+		Policy:                expandDeliveryPolicy(d.Get("policy").([]interface{})),
+	}
+
+	if !d.Get("skip_credentials_validation").(bool) {
+		if meta.DefaultShortname == "" {
+			// The checks list the objects of an account, so only the API key can be checked.
+			log.Printf("[INFO] Only checking the format of the API key, as default_shortname isn't set")
+			if err := validateAPIKey(meta.ConfigurationClient.apiClient); err != nil {
+				return nil, err
+			}
+		} else if err := validateCredentials(credentialsChecks(meta, meta.DefaultShortname)); err != nil {
+			return nil, err
+		}
+	}
+
+	return meta, nil
+}
+
+func expandHTTPClientConfig(d *schema.ResourceData) httpClientConfig {
+	config := httpClientConfig{
+		ProxyURL:           d.Get("proxy_url").(string),
+		CABundleFile:       d.Get("ca_bundle_file").(string),
+		InsecureSkipVerify: d.Get("insecure_skip_verify").(bool),
+	}
+
+	// http_timeout is validated as a duration by the schema.
+	config.Timeout, _ = time.ParseDuration(d.Get("http_timeout").(string))
 
-	m["config"] = configurationClient
-	m["edgefunctions"] = edgeFunctionsClient
+	if clientCertificates := d.Get("client_certificate").([]interface{}); len(clientCertificates) > 0 && clientCertificates[0] != nil {
+		clientCertificate := clientCertificates[0].(map[string]interface{})
+		config.ClientCertFile = clientCertificate["cert_file"].(string)
+		config.ClientKeyFile = clientCertificate["key_file"].(string)
+	}
+
+	return config
+}
 
-	return m, nil
+func getConfigurationClient(m interface{}) *configurationClient {
+	return getMeta(m).ConfigurationClient
 }
 
-func getConfigurationClient(m interface{}) *configuration.ConfigurationClient {
-	clients := m.(map[string]interface{})
-	return clients["config"].(*configuration.ConfigurationClient)
+func getEdgeFunctionsClient(m interface{}) *edgeFunctionsClient {
+	return getMeta(m).EdgeFunctionsClient
 }
 
-func getEdgeFunctionsClient(m interface{}) *edgefunctions.EdgeFunctionsClient {
-	clients := m.(map[string]interface{})
-	return clients["edgefunctions"].(*edgefunctions.EdgeFunctionsClient)
+func getPurgeClient(m interface{}) *purgeClient {
+	return getMeta(m).PurgeClient
+}
+
+func getReportingClient(m interface{}) *reportingClient {
+	return getMeta(m).ReportingClient
+}
+
+// resourceGetter is implemented by both schema.ResourceData and schema.ResourceDiff, for
+// the helpers used when planning as well as in the CRUD functions.
+type resourceGetter interface {
+	Get(key string) interface{}
+}
+
+// providerDefault is the value of the shortname and service_profile arguments of the
+// resources when they aren't set in the configuration, which the CustomizeDiff of the
+// resources replaces with the provider's default. The SDK doesn't otherwise tell an
+// argument that isn't set from one set to the value in the state.
+const providerDefault = "(provider default)"
+
+func providerDefaultFunc() (interface{}, error) {
+	return providerDefault, nil
+}
+
+// customizeDiffDefaultShortname plans the provider's default_shortname as the shortname
+// of resources that don't set one, so that changing default_shortname or removing
+// shortname from the configuration changes the resource.
+func customizeDiffDefaultShortname(d *schema.ResourceDiff, m interface{}) error {
+	if d.Get("shortname").(string) != providerDefault {
+		return nil
+	}
+
+	shortname := getMeta(m).DefaultShortname
+	if shortname == "" {
+		return fmt.Errorf("shortname must be set on the resource or as default_shortname on the provider")
+	}
+
+	return d.SetNew("shortname", shortname)
+}
+
+// customizeDiffDefaultServiceProfile plans the provider's default_service_profile as
+// the service profile of delivery configurations that don't set one.
+func customizeDiffDefaultServiceProfile(d *schema.ResourceDiff, m interface{}) error {
+	if d.Get("service_profile").(string) != providerDefault {
+		return nil
+	}
+
+	return d.SetNew("service_profile", resolveServiceProfile(d, m))
+}
+
+// resolveShortname returns the shortname set on the resource, falling back to the
+// provider's default_shortname.
+func resolveShortname(d *schema.ResourceData, m interface{}) (string, error) {
+	if shortname := d.Get("shortname").(string); shortname != "" && shortname != providerDefault {
+		return shortname, nil
+	}
+
+	if shortname := getMeta(m).DefaultShortname; shortname != "" {
+		return shortname, nil
+	}
+
+	return "", fmt.Errorf("shortname must be set on the resource or as default_shortname on the provider")
+}
+
+// resolveServiceProfile returns the service profile set on the resource, falling back
+// to the provider's default_service_profile and then to LLNW-Generic.
+func resolveServiceProfile(d resourceGetter, m interface{}) string {
+	if serviceProfile := d.Get("service_profile").(string); serviceProfile != "" && serviceProfile != providerDefault {
+		return serviceProfile
+	}
+
+	if serviceProfile := getMeta(m).DefaultServiceProfile; serviceProfile != "" {
+		return serviceProfile
+	}
+
+	return defaultServiceProfile
 }
@@ -2,7 +2,10 @@ package limelight
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
@@ -29,6 +32,78 @@ func TestProvider_impl(t *testing.T) {
 	var _ terraform.ResourceProvider = Provider()
 }
 
+func TestProviderConfigureTransport(t *testing.T) {
+	var principal string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal = r.Header.Get(headerSecurityPrincipal)
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	defaultTransport := http.DefaultTransport
+	p := Provider().(*schema.Provider)
+	err := p.Configure(terraform.NewResourceConfigRaw(map[string]interface{}{
+		"username":                    "user",
+		"api_key":                     "00",
+		"purge_api_base_url":          server.URL,
+		"read_only":                   true,
+		"skip_credentials_validation": true,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if http.DefaultTransport != defaultTransport {
+		t.Error("expected http.DefaultTransport to be left unchanged")
+	}
+
+	client := getMeta(p.Meta()).PurgeClient
+	if _, _, err := client.get(server.URL + "/requests"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if principal != "user" {
+		t.Errorf("expected a request signed by user, got %q", principal)
+	}
+	if _, _, err := client.request(http.MethodPost, server.URL+"/requests", nil, client.readTimeout); err == nil {
+		t.Error("expected the provider's read-only transport to reject the request")
+	}
+	if _, err := http.Post(server.URL+"/requests", "application/json", nil); err != nil {
+		t.Errorf("expected other requests of the process to be allowed, got %s", err)
+	}
+}
+
+func TestProviderConfigureWithoutDefaultShortname(t *testing.T) {
+	if os.Getenv("LLNW_DEFAULT_SHORTNAME") != "" || os.Getenv("LLNW_SKIP_CREDENTIALS_VALIDATION") != "" {
+		t.Skip("LLNW_DEFAULT_SHORTNAME or LLNW_SKIP_CREDENTIALS_VALIDATION is set")
+	}
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	configure := func(apiKey string) error {
+		return Provider().(*schema.Provider).Configure(terraform.NewResourceConfigRaw(map[string]interface{}{
+			"username":                   "user",
+			"api_key":                    apiKey,
+			"config_api_base_url":        server.URL,
+			"edgefunctions_api_base_url": server.URL,
+		}))
+	}
+
+	if err := configure("00"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := configure("not hex"); err == nil || !strings.Contains(err.Error(), "invalid api_key") {
+		t.Errorf("expected an invalid api_key error, got %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("expected no requests, got %d", requests)
+	}
+}
+
 func testAccPreCheck(t *testing.T) {
 	var requiredVariables = []string{"LLNW_API_USERNAME", "LLNW_API_KEY", "LLNW_TEST_SHORTNAME"}
 	for _, element := range requiredVariables {
@@ -0,0 +1,71 @@
+package limelight
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const defaultPurgeAPIBaseURL = "https://purge.llnw.com/purge/v1"
+
+const (
+	purgeStatePending    = "PENDING"
+	purgeStateInProgress = "IN_PROGRESS"
+	purgeStateCompleted  = "COMPLETED"
+	purgeStateFailed     = "FAILED"
+)
+
+// purgeClient is a client for the Limelight Purge API, which the SDK doesn't cover.
+type purgeClient struct {
+	*apiClient
+}
+
+func newPurgeClient(apiUser string, apiKey string, baseURL string) *purgeClient {
+	if baseURL == "" {
+		baseURL = defaultPurgeAPIBaseURL
+	}
+
+	return &purgeClient{newAPIClient(apiUser, apiKey, baseURL)}
+}
+
+type purgePattern struct {
+	Pattern            string `json:"pattern"`
+	Evict              bool   `json:"evict"`
+	Exact              bool   `json:"exact"`
+	IncludeQueryString bool   `json:"incqs"`
+}
+
+type purgeRequest struct {
+	Patterns []purgePattern `json:"patterns"`
+}
+
+type purgeRequestStatus struct {
+	ID    string `json:"id"`
+	State string `json:"state"`
+}
+
+func (c *purgeClient) CreatePurgeRequest(shortname string, request *purgeRequest) (*purgeRequestStatus, *http.Response, error) {
+	body, response, err := c.request(http.MethodPost, fmt.Sprintf("%s/account/%s/requests", c.BaseUrl, shortname), request, c.readTimeout)
+	if err != nil {
+		return nil, response, err
+	}
+
+	status := &purgeRequestStatus{}
+	if err = json.Unmarshal(body, status); err != nil {
+		return nil, response, err
+	}
+	return status, response, nil
+}
+
+func (c *purgeClient) GetPurgeRequestStatus(shortname string, id string) (*purgeRequestStatus, *http.Response, error) {
+	body, response, err := c.get(fmt.Sprintf("%s/account/%s/requests/%s", c.BaseUrl, shortname, id))
+	if err != nil {
+		return nil, response, err
+	}
+
+	status := &purgeRequestStatus{}
+	if err = json.Unmarshal(body, status); err != nil {
+		return nil, response, err
+	}
+	return status, response, nil
+}
@@ -0,0 +1,58 @@
+package limelight
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+// guardReadOnlyResource makes the Create, Update and Delete functions of a resource fail
+// before making any API call when the provider is configured with read_only.
+func guardReadOnlyResource(resourceType string, r *schema.Resource) {
+	if r.Create != nil {
+		r.Create = guardReadOnly(resourceType, "create", r.Create)
+	}
+	if r.Update != nil {
+		r.Update = guardReadOnly(resourceType, "update", r.Update)
+	}
+	if r.Delete != nil {
+		r.Delete = guardReadOnly(resourceType, "delete", r.Delete)
+	}
+}
+
+func guardReadOnly(resourceType string, operation string, f func(*schema.ResourceData, interface{}) error) func(*schema.ResourceData, interface{}) error {
+	return func(d *schema.ResourceData, m interface{}) error {
+		if isReadOnly(m) {
+			target := resourceType
+			if d.Id() != "" {
+				target = fmt.Sprintf("%s %s", resourceType, d.Id())
+			}
+			return fmt.Errorf("refusing to %s %s: the provider is configured with read_only = true", operation, target)
+		}
+		return f(d, m)
+	}
+}
+
+func isReadOnly(m interface{}) bool {
+	meta, ok := m.(*Meta)
+	return ok && meta.ReadOnly
+}
+
+// readOnlyTransport rejects every request that could change anything, as a safeguard
+// behind the checks in the resources.
+type readOnlyTransport struct {
+	transport http.RoundTripper
+}
+
+func (t *readOnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return t.transport.RoundTrip(req)
+	}
+
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, fmt.Errorf("refusing to send %s %s: the provider is configured with read_only = true", req.Method, redactURL(req.URL))
+}
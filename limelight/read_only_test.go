@@ -0,0 +1,64 @@
+package limelight
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestReadOnlyResources(t *testing.T) {
+	provider := Provider().(*schema.Provider)
+	// The clients are left out of the meta, so any call that gets past the guard panics.
+	m := &Meta{ReadOnly: true}
+
+	for resourceType, r := range provider.ResourcesMap {
+		d := r.Data(nil)
+		d.SetId("id")
+
+		operations := map[string]func(*schema.ResourceData, interface{}) error{
+			"create": r.Create,
+			"update": r.Update,
+			"delete": r.Delete,
+		}
+		for operation, f := range operations {
+			if f == nil {
+				continue
+			}
+			err := f(d, m)
+			if err == nil || !strings.Contains(err.Error(), "read_only") {
+				t.Errorf("%s %s: expected a read_only error, got %v", operation, resourceType, err)
+			}
+		}
+	}
+}
+
+func TestReadOnlyTransport(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &readOnlyTransport{transport: http.DefaultTransport}}
+
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodPatch} {
+		req, _ := http.NewRequest(method, server.URL, strings.NewReader("{}"))
+		resp, err := client.Do(req)
+		if method == http.MethodGet {
+			if err != nil {
+				t.Fatalf("GET: unexpected error: %s", err)
+			}
+			resp.Body.Close()
+		} else if err == nil {
+			resp.Body.Close()
+			t.Errorf("%s: expected an error", method)
+		}
+	}
+
+	if len(methods) != 1 || methods[0] != http.MethodGet {
+		t.Errorf("expected only a GET to reach the server, got %v", methods)
+	}
+}
@@ -0,0 +1,90 @@
+package limelight
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const defaultReportingAPIBaseURL = "https://apis.llnw.com/reporting-api/v1"
+
+// reportingClient is a client for the Limelight Reporting API, which the SDK doesn't
+// cover.
+type reportingClient struct {
+	*apiClient
+}
+
+func newReportingClient(apiUser string, apiKey string, baseURL string) *reportingClient {
+	if baseURL == "" {
+		baseURL = defaultReportingAPIBaseURL
+	}
+
+	return &reportingClient{newAPIClient(apiUser, apiKey, baseURL)}
+}
+
+// usageReportQuery selects the traffic of an account over a time range, optionally for
+// some published hostnames only.
+type usageReportQuery struct {
+	Start              time.Time
+	End                time.Time
+	PublishedHostnames []string
+}
+
+// usageReportRow is the traffic of a published hostname. StatusCodes counts the
+// requests by HTTP status code.
+type usageReportRow struct {
+	PublishedHostname string           `json:"publishedHostname"`
+	Bytes             int64            `json:"bytes"`
+	Requests          int64            `json:"requests"`
+	CacheHits         int64            `json:"cacheHits"`
+	StatusCodes       map[string]int64 `json:"statusCodes"`
+}
+
+// cacheHitRatio returns the share of requests served from cache, or 0 without requests.
+func (r usageReportRow) cacheHitRatio() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+	return float64(r.CacheHits) / float64(r.Requests)
+}
+
+type usageReport struct {
+	Results []usageReportRow `json:"results"`
+}
+
+func (c *reportingClient) GetUsageReport(shortname string, query usageReportQuery) (*usageReport, *http.Response, error) {
+	values := url.Values{}
+	values.Set("start", strconv.FormatInt(query.Start.UnixNano()/int64(time.Millisecond), 10))
+	values.Set("end", strconv.FormatInt(query.End.UnixNano()/int64(time.Millisecond), 10))
+	for _, hostname := range query.PublishedHostnames {
+		values.Add("publishedHostname", hostname)
+	}
+
+	body, response, err := c.get(fmt.Sprintf("%s/traffic/%s?%s", c.BaseUrl, shortname, values.Encode()))
+	if err != nil {
+		return nil, response, err
+	}
+
+	report := &usageReport{}
+	if err = json.Unmarshal(body, report); err != nil {
+		return nil, response, fmt.Errorf("error decoding usage report: %s", err)
+	}
+	return report, response, nil
+}
+
+// total sums the rows of the report.
+func (r *usageReport) total() usageReportRow {
+	total := usageReportRow{StatusCodes: map[string]int64{}}
+	for _, row := range r.Results {
+		total.Bytes += row.Bytes
+		total.Requests += row.Requests
+		total.CacheHits += row.CacheHits
+		for code, count := range row.StatusCodes {
+			total.StatusCodes[code] += count
+		}
+	}
+	return total
+}
@@ -4,13 +4,23 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"strconv"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/structure"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/llnw/llnw-sdk-go/configuration"
 )
 
+const (
+	defaultServiceProfile = "LLNW-Generic"
+	deliveryServiceKey    = "delivery"
+)
+
 func resourceLimelightDelivery() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceLimelightDeliveryCreate,
@@ -20,21 +30,38 @@ func resourceLimelightDelivery() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		CustomizeDiff: customdiff.Sequence(
+			customizeDiffDefaultShortname,
+			customizeDiffDefaultServiceProfile,
+			customizeDiffDeliveryPolicy,
+			customdiff.ComputedIf("published_urls", func(d *schema.ResourceDiff, m interface{}) bool {
+				return d.HasChange("protocol_set") || d.HasChange("published_hostname") || d.HasChange("published_path") || d.HasChange("body_json")
+			}),
+		),
 		Schema: map[string]*schema.Schema{
 			"shortname": &schema.Schema{
-				Type:     schema.TypeString,
-				Required: true,
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				DefaultFunc: providerDefaultFunc,
 			},
 			"service_profile": &schema.Schema{
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  "LLNW-Generic",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				DefaultFunc: providerDefaultFunc,
 			},
 			"protocol_set": &schema.Schema{
-				Type:     schema.TypeList,
-				Required: true,
-				MinItems: 1,
-				MaxItems: 2,
+				Type:             schema.TypeList,
+				Required:         true,
+				MinItems:         1,
+				MaxItems:         2,
+				DiffSuppressFunc: suppressEquivalentProtocolSets,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"published_protocol": &schema.Schema{
@@ -95,39 +122,71 @@ func resourceLimelightDelivery() *schema.Resource {
 				Type:     schema.TypeString,
 				Required: true,
 			},
+			"body_json": &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateFunc:     validation.StringIsJSON,
+				DiffSuppressFunc: structure.SuppressJsonDiff,
+			},
+			"rollback_to_revision": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"deletion_protection": deletionProtectionSchema(),
+			"purge_on_change": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
 			"version_number": &schema.Schema{
 				Type:     schema.TypeInt,
 				Computed: true,
 			},
+			"effective_body_json": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"published_urls": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
 		},
 	}
 }
 
 func resourceLimelightDeliveryCreate(d *schema.ResourceData, m interface{}) error {
-	c := getConfigurationClient(m)
+	meta := getMeta(m)
+	c := meta.Deliveries
 
-	shortname := d.Get("shortname").(string)
-	serviceProfile := d.Get("service_profile").(string)
-	protocolSets := expandProtocolSets(d.Get("protocol_set").([]interface{}), c, shortname, serviceProfile)
-	publishedHostname := d.Get("published_hostname").(string)
-	publishedPath := d.Get("published_path").(string)
-	sourceHostname := d.Get("source_hostname").(string)
-	sourcePath := d.Get("source_path").(string)
+	shortname, err := resolveShortname(d, m)
+	if err != nil {
+		return err
+	}
+	serviceProfile := resolveServiceProfile(d, m)
+	argumentTypes, err := meta.OptionArgumentTypes.get(c, shortname, serviceProfile)
+	if err != nil {
+		log.Printf("[WARN] Sending option parameters as strings: %s", err)
+	}
 
-	body := &configuration.DeliveryServiceInstanceBody{
-		ServiceProfileName: serviceProfile,
-		ProtocolSets:       protocolSets,
-		PublishedHostname:  publishedHostname,
-		PublishedURLPath:   publishedPath,
-		SourceHostname:     sourceHostname,
-		SourceURLPath:      sourcePath,
-		ServiceKey: configuration.ServiceKey{
-			Name: "delivery",
-		},
+	requestBody, err := deliveryRequestBody(d, serviceProfile, argumentTypes)
+	if err != nil {
+		return err
+	}
+
+	if err := checkDeliveryPolicy(meta.Policy, requestBody); err != nil {
+		return err
 	}
 
 	log.Printf("[INFO] Creating delivery configuration for service profile: %s", serviceProfile)
-	deliveryServiceInstance, _, err := c.CreateDeliveryServiceInstance(body, shortname)
+	var deliveryServiceInstance *serviceInstance
+	err = retryAPICreate(d.Timeout(schema.TimeoutCreate), func(remaining time.Duration) (resp *http.Response, err error) {
+		deliveryServiceInstance, resp, err = c.CreateDelivery(requestBody, shortname, remaining)
+		return resp, err
+	})
 
 	if err != nil {
 		return fmt.Errorf("error creating delivery configuration: %s", err)
@@ -139,75 +198,169 @@ func resourceLimelightDeliveryCreate(d *schema.ResourceData, m interface{}) erro
 }
 
 func resourceLimelightDeliveryRead(d *schema.ResourceData, m interface{}) error {
-	c := getConfigurationClient(m)
+	c := getMeta(m).Deliveries
 
 	log.Printf("[INFO] Fetching delivery configuration: %s", d.Id())
-	deliveryServiceInstance, resp, err := c.GetDeliveryServiceInstance(d.Id())
+	deliveryServiceInstance, resp, err := c.GetDelivery(d.Id())
 
 	if err != nil {
-		if resp != nil && resp.StatusCode == http.StatusNotFound {
-			log.Printf("[INFO] Delivery configuration %s not found", d.Id())
-			d.SetId("")
-			return nil
-		}
+		return handleReadError(d, resp, err, fmt.Sprintf("delivery configuration %s", d.Id()))
+	}
+
+	body := &configuration.DeliveryServiceInstanceBody{}
+	if err := deliveryServiceInstance.decodeBody(body); err != nil {
 		return fmt.Errorf("error reading delivery configuration: %s", err)
 	}
 
 	d.Set("shortname", deliveryServiceInstance.Shortname)
-	d.Set("service_profile", deliveryServiceInstance.Body.ServiceProfileName)
-	d.Set("published_hostname", deliveryServiceInstance.Body.PublishedHostname)
-	d.Set("published_path", deliveryServiceInstance.Body.PublishedURLPath)
-	d.Set("source_hostname", deliveryServiceInstance.Body.SourceHostname)
-	d.Set("source_path", deliveryServiceInstance.Body.SourceURLPath)
-	d.Set("version_number", deliveryServiceInstance.Revision.VersionNumber)
-	d.Set("protocol_set", flattenProtocolSets(deliveryServiceInstance.Body.ProtocolSets))
-
-	return nil
+	d.Set("service_profile", body.ServiceProfileName)
+	d.Set("published_hostname", body.PublishedHostname)
+	d.Set("published_path", body.PublishedURLPath)
+	d.Set("source_hostname", body.SourceHostname)
+	d.Set("source_path", body.SourceURLPath)
+	protocolSets := alignProtocolSets(flattenProtocolSets(body.ProtocolSets), d.Get("protocol_set").([]interface{}))
+	d.Set("protocol_set", protocolSets)
+	d.Set("published_urls", deliveryPublishedURLs(body.PublishedHostname, body.PublishedURLPath, protocolSets))
+	setDeletionProtectionDefault(d)
+
+	return setServiceInstanceRevision(d, deliveryServiceInstance)
 }
 
 func resourceLimelightDeliveryUpdate(d *schema.ResourceData, m interface{}) error {
-	c := getConfigurationClient(m)
+	meta := getMeta(m)
+	c := meta.Deliveries
 
-	shortname := d.Get("shortname").(string)
-	serviceProfile := d.Get("service_profile").(string)
-	protocolSets := expandProtocolSets(d.Get("protocol_set").([]interface{}), c, shortname, serviceProfile)
-	publishedHostname := d.Get("published_hostname").(string)
-	publishedPath := d.Get("published_path").(string)
-	sourceHostname := d.Get("source_hostname").(string)
-	sourcePath := d.Get("source_path").(string)
+	shortname, err := resolveShortname(d, m)
+	if err != nil {
+		return err
+	}
 
-	body := &configuration.DeliveryServiceInstanceBody{
-		ServiceProfileName: serviceProfile,
-		ProtocolSets:       protocolSets,
-		PublishedHostname:  publishedHostname,
-		PublishedURLPath:   publishedPath,
-		SourceHostname:     sourceHostname,
-		SourceURLPath:      sourcePath,
-		ServiceKey: configuration.ServiceKey{
-			Name: "delivery",
-		},
+	if revision := d.Get("rollback_to_revision").(int); revision != 0 && d.HasChange("rollback_to_revision") {
+		return resourceLimelightDeliveryRollback(d, m, shortname, revision)
+	}
+
+	serviceProfile := resolveServiceProfile(d, m)
+	argumentTypes, err := meta.OptionArgumentTypes.get(c, shortname, serviceProfile)
+	if err != nil {
+		log.Printf("[WARN] Sending option parameters as strings: %s", err)
+	}
+
+	requestBody, err := deliveryRequestBody(d, serviceProfile, argumentTypes)
+	if err != nil {
+		return err
+	}
+
+	if err := checkDeliveryPolicy(meta.Policy, requestBody); err != nil {
+		return err
 	}
 
 	log.Printf("[INFO] Updating delivery configuration for: %s", d.Id())
-	_, _, err := c.UpdateDeliveryServiceInstance(d.Id(), body, shortname)
+	err = retryAPICall(d.Timeout(schema.TimeoutUpdate), func(remaining time.Duration) (*http.Response, error) {
+		_, resp, err := c.UpdateDelivery(d.Id(), requestBody, shortname, remaining)
+		return resp, err
+	})
 
 	if err != nil {
 		return fmt.Errorf("error updating delivery configuration: %s", err)
 	}
 
+	if d.Get("purge_on_change").(bool) && d.HasChanges("protocol_set", "published_hostname", "published_path", "source_hostname", "source_path", "body_json") {
+		if err := purgeLimelightDelivery(d, m, shortname); err != nil {
+			return err
+		}
+	}
+
+	return resourceLimelightDeliveryRead(d, m)
+}
+
+// resourceLimelightDeliveryRollback restores the body of an earlier revision of the
+// delivery configuration as a new revision. The other arguments are not applied, and the
+// state is read back from the API so that it reflects the restored revision.
+func resourceLimelightDeliveryRollback(d *schema.ResourceData, m interface{}, shortname string, versionNumber int) error {
+	c := getMeta(m).Deliveries
+
+	log.Printf("[INFO] Fetching revisions of delivery configuration: %s", d.Id())
+	revisions, _, err := c.GetDeliveryRevisions(d.Id())
+
+	if err != nil {
+		return fmt.Errorf("error reading delivery configuration revisions: %s", err)
+	}
+
+	var revision *serviceInstance
+	for i := range revisions {
+		if revisions[i].Revision.VersionNumber == versionNumber {
+			revision = &revisions[i]
+			break
+		}
+	}
+
+	if revision == nil {
+		return fmt.Errorf("delivery configuration %s has no revision %d", d.Id(), versionNumber)
+	}
+
+	if err := checkDeliveryPolicy(getMeta(m).Policy, revision.Body); err != nil {
+		return fmt.Errorf("cannot roll back to revision %d: %s", versionNumber, err)
+	}
+
+	log.Printf("[INFO] Rolling back delivery configuration %s to revision %d", d.Id(), versionNumber)
+	err = retryAPICall(d.Timeout(schema.TimeoutUpdate), func(remaining time.Duration) (*http.Response, error) {
+		_, resp, err := c.UpdateDelivery(d.Id(), revision.Body, shortname, remaining)
+		return resp, err
+	})
+
+	if err != nil {
+		return fmt.Errorf("error rolling back delivery configuration to revision %d: %s", versionNumber, err)
+	}
+
+	if d.Get("purge_on_change").(bool) {
+		if err := purgeLimelightDelivery(d, m, shortname); err != nil {
+			return err
+		}
+	}
+
 	return resourceLimelightDeliveryRead(d, m)
 }
 
 func resourceLimelightDeliveryDelete(d *schema.ResourceData, m interface{}) error {
-	c := getConfigurationClient(m)
+	c := getMeta(m).Deliveries
+
+	if err := checkDeletionProtection(d, fmt.Sprintf("delivery configuration %s", d.Id())); err != nil {
+		return err
+	}
 
 	log.Printf("[INFO] Deleting delivery configuration: %s", d.Id())
-	_, _, err := c.DeleteDeliveryServiceInstance(d.Id())
+	var resp *http.Response
+	err := retryAPICall(d.Timeout(schema.TimeoutDelete), func(remaining time.Duration) (*http.Response, error) {
+		var err error
+		resp, err = c.DeleteDelivery(d.Id(), remaining)
+		return resp, err
+	})
+
+	return handleDeleteError(resp, err, fmt.Sprintf("delivery configuration %s", d.Id()))
+}
+
+// purgeLimelightDelivery submits a purge of everything published by a delivery
+// configuration. It doesn't wait for the purge to complete.
+func purgeLimelightDelivery(d *schema.ResourceData, m interface{}, shortname string) error {
+	c := getPurgeClient(m)
+
+	request := &purgeRequest{}
+	for _, v := range d.Get("protocol_set").([]interface{}) {
+		rawProtocolSet := v.(map[string]interface{})
+		request.Patterns = append(request.Patterns, purgePattern{
+			Pattern: fmt.Sprintf("%s://%s%s*", rawProtocolSet["published_protocol"], d.Get("published_hostname"), d.Get("published_path")),
+		})
+	}
+
+	log.Printf("[INFO] Purging delivery configuration: %s", d.Id())
+	status, _, err := c.CreatePurgeRequest(shortname, request)
 
 	if err != nil {
-		return fmt.Errorf("error deleting delivery configuration: %s", err)
+		return fmt.Errorf("delivery configuration was updated, but purging its content failed: %s", err)
 	}
 
+	log.Printf("[INFO] Submitted purge request %s for delivery configuration %s", status.ID, d.Id())
+
 	return nil
 }
 
@@ -226,6 +379,135 @@ func flattenProtocolSets(expandedProtocolSets []configuration.ProtocolSet) []map
 	return flattenedProtocolSets
 }
 
+// suppressEquivalentProtocolSets suppresses diffs between protocol sets that differ only
+// in order, see protocolSetsEquivalent.
+func suppressEquivalentProtocolSets(k, old, new string, d *schema.ResourceData) bool {
+	o, n := d.GetChange("protocol_set")
+	return protocolSetsEquivalent(o.([]interface{}), n.([]interface{}))
+}
+
+// protocolSetsEquivalent reports whether two lists of flattened protocol sets are the
+// same, ignoring the order of the protocol sets and the relative order of options with
+// different names. Options that are repeated are applied in order, so the relative order
+// of options with the same name is significant.
+func protocolSetsEquivalent(a []interface{}, b []interface{}) bool {
+	return reflect.DeepEqual(canonicalProtocolSets(a), canonicalProtocolSets(b))
+}
+
+func canonicalProtocolSets(flattenedProtocolSets []interface{}) map[string]interface{} {
+	canonical := make(map[string]interface{}, len(flattenedProtocolSets))
+
+	for _, v := range flattenedProtocolSets {
+		rawProtocolSet, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		var options []interface{}
+		if rawOptions, ok := rawProtocolSet["option"].([]interface{}); ok {
+			options = make([]interface{}, len(rawOptions))
+			copy(options, rawOptions)
+			sort.SliceStable(options, func(i, j int) bool {
+				return optionName(options[i]) < optionName(options[j])
+			})
+		}
+
+		canonical[fmt.Sprintf("%v", rawProtocolSet["published_protocol"])] = []interface{}{
+			rawProtocolSet["source_protocol"],
+			rawProtocolSet["source_port"],
+			options,
+		}
+	}
+
+	return canonical
+}
+
+func optionName(v interface{}) string {
+	if rawOption, ok := v.(map[string]interface{}); ok {
+		if name, ok := rawOption["name"].(string); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// alignProtocolSets orders the protocol sets and options read from the API to match
+// their order in the prior state or configuration, so that a reordering by the API
+// doesn't produce a diff. Protocol sets are matched by published_protocol, and the
+// n-th occurrence of an option is matched to the n-th occurrence of an option with the
+// same name. Anything without a match keeps its API order after the matched items.
+func alignProtocolSets(flattenedProtocolSets []map[string]interface{}, prior []interface{}) []map[string]interface{} {
+	priorByProtocol := make(map[string]map[string]interface{}, len(prior))
+	priorPositions := make(map[string]int, len(prior))
+	for i, v := range prior {
+		if rawProtocolSet, ok := v.(map[string]interface{}); ok {
+			protocol := fmt.Sprintf("%v", rawProtocolSet["published_protocol"])
+			priorByProtocol[protocol] = rawProtocolSet
+			priorPositions[protocol] = i
+		}
+	}
+
+	aligned := make([]map[string]interface{}, len(flattenedProtocolSets))
+	copy(aligned, flattenedProtocolSets)
+	sort.SliceStable(aligned, func(i, j int) bool {
+		return alignedPosition(priorPositions, fmt.Sprintf("%v", aligned[i]["published_protocol"])) <
+			alignedPosition(priorPositions, fmt.Sprintf("%v", aligned[j]["published_protocol"]))
+	})
+
+	for _, protocolSet := range aligned {
+		priorProtocolSet, ok := priorByProtocol[fmt.Sprintf("%v", protocolSet["published_protocol"])]
+		if !ok {
+			continue
+		}
+		priorOptions, _ := priorProtocolSet["option"].([]interface{})
+		protocolSet["option"] = alignOptions(protocolSet["option"].([]map[string]interface{}), priorOptions)
+	}
+
+	return aligned
+}
+
+func alignOptions(flattenedOptions []map[string]interface{}, prior []interface{}) []map[string]interface{} {
+	priorPositions := make(map[string]int, len(prior))
+	priorOccurrences := make(map[string]int)
+	for i, v := range prior {
+		name := optionName(v)
+		priorPositions[fmt.Sprintf("%s#%d", name, priorOccurrences[name])] = i
+		priorOccurrences[name]++
+	}
+
+	keys := make([]string, len(flattenedOptions))
+	occurrences := make(map[string]int)
+	for i, v := range flattenedOptions {
+		name := fmt.Sprintf("%v", v["name"])
+		keys[i] = fmt.Sprintf("%s#%d", name, occurrences[name])
+		occurrences[name]++
+	}
+
+	indexes := make([]int, len(flattenedOptions))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return alignedPosition(priorPositions, keys[indexes[i]]) < alignedPosition(priorPositions, keys[indexes[j]])
+	})
+
+	aligned := make([]map[string]interface{}, len(flattenedOptions))
+	for i, index := range indexes {
+		aligned[i] = flattenedOptions[index]
+	}
+
+	return aligned
+}
+
+// alignedPosition returns the position of key in the prior order, or a position after
+// all prior items if it has none.
+func alignedPosition(priorPositions map[string]int, key string) int {
+	if position, ok := priorPositions[key]; ok {
+		return position
+	}
+	return len(priorPositions)
+}
+
 func flattenOptions(expandedOptions []configuration.Option) []map[string]interface{} {
 	flattenedOptions := make([]map[string]interface{}, len(expandedOptions), len(expandedOptions))
 
@@ -234,12 +516,7 @@ func flattenOptions(expandedOptions []configuration.Option) []map[string]interfa
 		m["name"] = v.Name
 		params := make([]string, len(v.Parameters), len(v.Parameters))
 		for j, p := range v.Parameters {
-			switch p.(type) {
-			case float64:
-				params[j] = fmt.Sprintf("%.0f", p)
-			default:
-				params[j] = fmt.Sprintf("%v", p)
-			}
+			params[j] = flattenOptionParameter(p)
 		}
 		m["parameters"] = params
 		flattenedOptions[i] = m
@@ -248,7 +525,39 @@ func flattenOptions(expandedOptions []configuration.Option) []map[string]interfa
 	return flattenedOptions
 }
 
-func expandProtocolSets(flattenedProtocolSets []interface{}, c *configuration.ConfigurationClient, shortname string, serviceProfile string) []configuration.ProtocolSet {
+// deliveryPublishedURLs returns the URL content is published at for each protocol set, in
+// the order of the protocol sets.
+func deliveryPublishedURLs(publishedHostname string, publishedPath string, flattenedProtocolSets []map[string]interface{}) []string {
+	if !strings.HasPrefix(publishedPath, "/") {
+		publishedPath = "/" + publishedPath
+	}
+
+	urls := make([]string, len(flattenedProtocolSets))
+	for i, protocolSet := range flattenedProtocolSets {
+		urls[i] = fmt.Sprintf("%s://%s%s", protocolSet["published_protocol"], publishedHostname, publishedPath)
+	}
+	return urls
+}
+
+// deliveryRequestBody returns the service instance body for the arguments of a delivery,
+// with body_json merged into it.
+func deliveryRequestBody(d resourceGetter, serviceProfile string, argumentTypes map[string][]string) (map[string]interface{}, error) {
+	body := &configuration.DeliveryServiceInstanceBody{
+		ServiceProfileName: serviceProfile,
+		ProtocolSets:       expandProtocolSets(d.Get("protocol_set").([]interface{}), argumentTypes),
+		PublishedHostname:  d.Get("published_hostname").(string),
+		PublishedURLPath:   d.Get("published_path").(string),
+		SourceHostname:     d.Get("source_hostname").(string),
+		SourceURLPath:      d.Get("source_path").(string),
+		ServiceKey: configuration.ServiceKey{
+			Name: deliveryServiceKey,
+		},
+	}
+
+	return mergeBodyJSON(body, d.Get("body_json").(string))
+}
+
+func expandProtocolSets(flattenedProtocolSets []interface{}, argumentTypes map[string][]string) []configuration.ProtocolSet {
 	expandedProtocolSets := make([]configuration.ProtocolSet, len(flattenedProtocolSets), len(flattenedProtocolSets))
 
 	for i, v := range flattenedProtocolSets {
@@ -256,7 +565,7 @@ func expandProtocolSets(flattenedProtocolSets []interface{}, c *configuration.Co
 		protocolSet := configuration.ProtocolSet{
 			PublishedProtocol: rawProtocolSet["published_protocol"].(string),
 			SourceProtocol:    rawProtocolSet["source_protocol"].(string),
-			Options:           expandOptions(rawProtocolSet["option"].([]interface{}), c, shortname, serviceProfile),
+			Options:           expandOptions(rawProtocolSet["option"].([]interface{}), argumentTypes),
 		}
 
 		sourcePort := rawProtocolSet["source_port"].(int)
@@ -270,14 +579,15 @@ func expandProtocolSets(flattenedProtocolSets []interface{}, c *configuration.Co
 	return expandedProtocolSets
 }
 
-func expandOptions(flattenedOptions []interface{}, c *configuration.ConfigurationClient, shortname string, serviceProfile string) []configuration.Option {
+func expandOptions(flattenedOptions []interface{}, argumentTypes map[string][]string) []configuration.Option {
 	expandedOptions := make([]configuration.Option, len(flattenedOptions), len(flattenedOptions))
 
 	for i, v := range flattenedOptions {
 		rawOption := v.(map[string]interface{})
+		name := rawOption["name"].(string)
 		opt := configuration.Option{
-			Name:       rawOption["name"].(string),
-			Parameters: expandOptionParameters(rawOption["parameters"].([]interface{}), rawOption["name"].(string), c, shortname, serviceProfile),
+			Name:       name,
+			Parameters: expandOptionParameters(rawOption["parameters"].([]interface{}), argumentTypes[name]),
 		}
 		expandedOptions[i] = opt
 	}
@@ -285,22 +595,17 @@ func expandOptions(flattenedOptions []interface{}, c *configuration.Configuratio
 	return expandedOptions
 }
 
-func expandOptionParameters(flattenedOptionParams []interface{}, optionName string, c *configuration.ConfigurationClient, shortname string, serviceProfile string) []interface{} {
+// expandOptionParameters converts the string parameters of an option to the types of
+// the option's arguments. Parameters beyond the known arguments are sent as strings.
+func expandOptionParameters(flattenedOptionParams []interface{}, argumentTypes []string) []interface{} {
 	expandedOptionParams := make([]interface{}, len(flattenedOptionParams), len(flattenedOptionParams))
 
 	for i, v := range flattenedOptionParams {
-		stringVal := v.(string)
-
-		argInt, _ := c.IsOptionArgumentInteger(shortname, serviceProfile, optionName, i)
-		if argInt {
-			if intVal, err := strconv.Atoi(v.(string)); err == nil {
-				expandedOptionParams[i] = intVal
-			} else {
-				expandedOptionParams[i] = stringVal
-			}
-		} else {
-			expandedOptionParams[i] = stringVal
+		argumentType := ""
+		if i < len(argumentTypes) {
+			argumentType = argumentTypes[i]
 		}
+		expandedOptionParams[i] = expandOptionParameter(v.(string), argumentType)
 	}
 
 	return expandedOptionParams
@@ -3,10 +3,15 @@ package limelight
 import (
 	"fmt"
 	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/llnw/llnw-sdk-go/configuration"
 )
 
 func TestAccResourceLimelightDelivery_minimal(t *testing.T) {
@@ -35,6 +40,8 @@ func TestAccResourceLimelightDelivery_minimal(t *testing.T) {
 					resource.TestCheckResourceAttr(testResourceName, "protocol_set.0.published_protocol", "https"),
 					resource.TestCheckResourceAttr(testResourceName, "protocol_set.0.source_protocol", "https"),
 					resource.TestCheckResourceAttr(testResourceName, "protocol_set.0.option.#", "0"),
+					resource.TestCheckResourceAttr(testResourceName, "published_urls.0",
+						fmt.Sprintf("https://terraform-test-minimal.%s.s.llnwi.net/", getShortname())),
 				),
 			},
 		},
@@ -94,6 +101,61 @@ func TestAccResourceLimelightDelivery_update(t *testing.T) {
 	})
 }
 
+func TestAccResourceLimelightDelivery_providerDefaults(t *testing.T) {
+	testResourceName := "limelight_delivery.test_delivery"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		CheckDestroy: func(state *terraform.State) error {
+			return testAccLimelightDeliveryCheckDestroy(state)
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLimelightDeliveryProviderDefaultsTemplate(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccLimelightDeliveryExists(testResourceName),
+					resource.TestCheckResourceAttr(testResourceName, "shortname", getShortname()),
+					resource.TestCheckResourceAttr(testResourceName, "service_profile", "LLNW-Generic"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceLimelightDelivery_deletionProtection(t *testing.T) {
+	testResourceName := "limelight_delivery.test_delivery"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		CheckDestroy: func(state *terraform.State) error {
+			return testAccLimelightDeliveryCheckDestroy(state)
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLimelightDeliveryDeletionProtectionTemplate(true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccLimelightDeliveryExists(testResourceName),
+					resource.TestCheckResourceAttr(testResourceName, "deletion_protection", "true"),
+				),
+			},
+			{
+				Config:      testAccLimelightDeliveryDeletionProtectionTemplate(true),
+				Destroy:     true,
+				ExpectError: regexp.MustCompile("deletion_protection is enabled"),
+			},
+			{
+				Config: testAccLimelightDeliveryDeletionProtectionTemplate(false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccLimelightDeliveryExists(testResourceName),
+					resource.TestCheckResourceAttr(testResourceName, "deletion_protection", "false"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccResourceLimelightDelivery_importBasic(t *testing.T) {
 	testResourceName := "limelight_delivery.test_delivery"
 
@@ -116,8 +178,448 @@ func TestAccResourceLimelightDelivery_importBasic(t *testing.T) {
 	})
 }
 
+func TestProtocolSetsEquivalent(t *testing.T) {
+	option := func(name string, parameters ...interface{}) interface{} {
+		return map[string]interface{}{"name": name, "parameters": parameters}
+	}
+	protocolSet := func(protocol string, options ...interface{}) interface{} {
+		return map[string]interface{}{
+			"published_protocol": protocol,
+			"source_protocol":    protocol,
+			"source_port":        0,
+			"option":             options,
+		}
+	}
+
+	cases := []struct {
+		name       string
+		a          []interface{}
+		b          []interface{}
+		equivalent bool
+	}{
+		{
+			name:       "identical",
+			a:          []interface{}{protocolSet("https", option("genreply", "200"))},
+			b:          []interface{}{protocolSet("https", option("genreply", "200"))},
+			equivalent: true,
+		},
+		{
+			name:       "reordered protocol sets",
+			a:          []interface{}{protocolSet("http"), protocolSet("https")},
+			b:          []interface{}{protocolSet("https"), protocolSet("http")},
+			equivalent: true,
+		},
+		{
+			name:       "reordered options with different names",
+			a:          []interface{}{protocolSet("https", option("genreply", "200"), option("refresh_absmin", "60"))},
+			b:          []interface{}{protocolSet("https", option("refresh_absmin", "60"), option("genreply", "200"))},
+			equivalent: true,
+		},
+		{
+			name: "reordered repeated options",
+			a: []interface{}{protocolSet("https",
+				option("reply_send_header", "X-A", "1"), option("reply_send_header", "X-B", "2"))},
+			b: []interface{}{protocolSet("https",
+				option("reply_send_header", "X-B", "2"), option("reply_send_header", "X-A", "1"))},
+			equivalent: false,
+		},
+		{
+			name:       "changed parameter",
+			a:          []interface{}{protocolSet("https", option("genreply", "200"))},
+			b:          []interface{}{protocolSet("https", option("genreply", "404"))},
+			equivalent: false,
+		},
+		{
+			name:       "added protocol set",
+			a:          []interface{}{protocolSet("https")},
+			b:          []interface{}{protocolSet("https"), protocolSet("http")},
+			equivalent: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if equivalent := protocolSetsEquivalent(tc.a, tc.b); equivalent != tc.equivalent {
+				t.Errorf("expected equivalent to be %t, got %t", tc.equivalent, equivalent)
+			}
+		})
+	}
+}
+
+func TestAlignProtocolSets(t *testing.T) {
+	prior := []interface{}{
+		map[string]interface{}{
+			"published_protocol": "https",
+			"option": []interface{}{
+				map[string]interface{}{"name": "reply_send_header", "parameters": []interface{}{"X-A", "1"}},
+				map[string]interface{}{"name": "genreply", "parameters": []interface{}{"200"}},
+				map[string]interface{}{"name": "reply_send_header", "parameters": []interface{}{"X-B", "2"}},
+			},
+		},
+		map[string]interface{}{
+			"published_protocol": "http",
+			"option":             []interface{}{},
+		},
+	}
+
+	fromAPI := flattenProtocolSets([]configuration.ProtocolSet{
+		{PublishedProtocol: "http", SourceProtocol: "http"},
+		{
+			PublishedProtocol: "https",
+			SourceProtocol:    "https",
+			Options: []configuration.Option{
+				{Name: "genreply", Parameters: []interface{}{"200"}},
+				{Name: "reply_send_header", Parameters: []interface{}{"X-A", "1"}},
+				{Name: "reply_send_header", Parameters: []interface{}{"X-B", "2"}},
+			},
+		},
+	})
+
+	aligned := alignProtocolSets(fromAPI, prior)
+
+	if aligned[0]["published_protocol"] != "https" || aligned[1]["published_protocol"] != "http" {
+		t.Fatalf("expected protocol sets in prior order, got %v", aligned)
+	}
+
+	var names []string
+	for _, option := range aligned[0]["option"].([]map[string]interface{}) {
+		names = append(names, fmt.Sprintf("%s%v", option["name"], option["parameters"]))
+	}
+	expected := "reply_send_header[X-A 1],genreply[200],reply_send_header[X-B 2]"
+	if strings.Join(names, ",") != expected {
+		t.Errorf("expected options %s, got %s", expected, strings.Join(names, ","))
+	}
+}
+
+func TestDeliveryPublishedURLs(t *testing.T) {
+	protocolSets := []map[string]interface{}{
+		{"published_protocol": "https", "source_protocol": "https"},
+		{"published_protocol": "http", "source_protocol": "http"},
+	}
+
+	actual := deliveryPublishedURLs("www.example.com", "assets/", protocolSets)
+	expected := []string{"https://www.example.com/assets/", "http://www.example.com/assets/"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestResourceLimelightDeliveryProviderDefaults(t *testing.T) {
+	r := resourceLimelightDelivery()
+	state := &terraform.InstanceState{
+		ID: "uuid-1",
+		Attributes: map[string]string{
+			"id":                                "uuid-1",
+			"shortname":                         "example",
+			"service_profile":                   defaultServiceProfile,
+			"published_hostname":                "www.example.com",
+			"published_path":                    "/",
+			"source_hostname":                   "origin.example.com",
+			"source_path":                       "/",
+			"protocol_set.#":                    "1",
+			"protocol_set.0.published_protocol": "https",
+			"protocol_set.0.source_protocol":    "https",
+			"protocol_set.0.source_port":        "0",
+			"protocol_set.0.option.#":           "0",
+			"deletion_protection":               "false",
+			"purge_on_change":                   "false",
+		},
+	}
+	withoutDefaults := testDeliveryConfig("/")
+	delete(withoutDefaults, "shortname")
+
+	cases := []struct {
+		name                   string
+		config                 map[string]interface{}
+		meta                   *Meta
+		expectedShortname      string
+		expectedServiceProfile string
+		expectedError          string
+	}{
+		{
+			name:   "unchanged defaults",
+			config: withoutDefaults,
+			meta:   &Meta{DefaultShortname: "example"},
+		},
+		{
+			name:              "changed default_shortname",
+			config:            withoutDefaults,
+			meta:              &Meta{DefaultShortname: "other"},
+			expectedShortname: "other",
+		},
+		{
+			name:                   "changed default_service_profile",
+			config:                 withoutDefaults,
+			meta:                   &Meta{DefaultShortname: "example", DefaultServiceProfile: "LLNW-Custom"},
+			expectedServiceProfile: "LLNW-Custom",
+		},
+		{
+			name:   "set on the resource",
+			config: testPolicyDeliveryConfig(map[string]interface{}{"service_profile": defaultServiceProfile}),
+			meta:   &Meta{DefaultShortname: "other", DefaultServiceProfile: "LLNW-Custom"},
+		},
+		{
+			name:          "no default_shortname",
+			config:        withoutDefaults,
+			meta:          &Meta{},
+			expectedError: "shortname must be set on the resource or as default_shortname on the provider",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			diff, err := r.Diff(state, terraform.NewResourceConfigRaw(c.config), c.meta)
+			if c.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), c.expectedError) {
+					t.Errorf("expected an error containing %q, got %v", c.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			for key, expected := range map[string]string{"shortname": c.expectedShortname, "service_profile": c.expectedServiceProfile} {
+				var attr *terraform.ResourceAttrDiff
+				if diff != nil {
+					attr = diff.Attributes[key]
+				}
+				switch {
+				case expected == "" && attr != nil:
+					t.Errorf("expected no change of %s, got %q => %q", key, attr.Old, attr.New)
+				case expected != "" && (attr == nil || attr.New != expected):
+					t.Errorf("expected %s to change to %q, got %#v", key, expected, attr)
+				}
+			}
+		})
+	}
+}
+
+func testDeliveryConfig(sourcePath string) map[string]interface{} {
+	return map[string]interface{}{
+		"shortname":          "example",
+		"published_hostname": "www.example.com",
+		"published_path":     "/",
+		"source_hostname":    "origin.example.com",
+		"source_path":        sourcePath,
+		"protocol_set": []interface{}{
+			map[string]interface{}{"published_protocol": "https", "source_protocol": "https"},
+		},
+	}
+}
+
+// testCreateFakeDelivery adds a delivery configuration with the ID uuid-1 to the fake,
+// with a revision for each source path.
+func testCreateFakeDelivery(t *testing.T, m *Meta, sourcePaths ...string) {
+	fake := m.Deliveries.(*fakeDeliveryAPI)
+	for _, sourcePath := range sourcePaths {
+		body, err := toJSONObject(&configuration.DeliveryServiceInstanceBody{
+			ServiceProfileName: defaultServiceProfile,
+			ProtocolSets: []configuration.ProtocolSet{
+				{PublishedProtocol: "https", SourceProtocol: "https"},
+			},
+			PublishedHostname: "www.example.com",
+			PublishedURLPath:  "/",
+			SourceHostname:    "origin.example.com",
+			SourceURLPath:     sourcePath,
+			ServiceKey:        configuration.ServiceKey{Name: deliveryServiceKey},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fake.latest("uuid-1") == nil {
+			fake.CreateDelivery(body, "example", 0)
+		} else {
+			fake.UpdateDelivery("uuid-1", body, "example", 0)
+		}
+	}
+}
+
+func testFakeDeliverySourcePath(m *Meta) interface{} {
+	latest := m.Deliveries.(*fakeDeliveryAPI).latest("uuid-1")
+	if latest == nil {
+		return nil
+	}
+	return latest.Body["sourceUrlPath"]
+}
+
+func TestResourceLimelightDeliveryOperations(t *testing.T) {
+	runResourceTestCases(t, resourceLimelightDelivery(), []resourceTestCase{
+		{
+			name:      "create",
+			operation: "create",
+			config:    testDeliveryConfig("/"),
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if d.Id() != "uuid-1" || d.Get("version_number") != 1 || d.Get("service_profile") != defaultServiceProfile {
+					t.Errorf("unexpected state %v", d.State())
+				}
+				if testFakeDeliverySourcePath(m) != "/" {
+					t.Errorf("unexpected body %v", m.Deliveries.(*fakeDeliveryAPI).latest("uuid-1").Body)
+				}
+			},
+		},
+		{
+			name:          "create failure",
+			operation:     "create",
+			config:        testDeliveryConfig("/"),
+			failures:      fakeFailures{"CreateDelivery": http.StatusBadRequest},
+			expectedError: "error creating delivery configuration",
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if d.Id() != "" {
+					t.Errorf("expected no ID, got %s", d.Id())
+				}
+			},
+		},
+		{
+			name:      "read",
+			setup:     func(m *Meta) { testCreateFakeDelivery(t, m, "/", "/v2") },
+			operation: "read",
+			id:        "uuid-1",
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if d.Get("shortname") != "example" || d.Get("source_path") != "/v2" || d.Get("version_number") != 2 || d.Get("protocol_set.0.published_protocol") != "https" {
+					t.Errorf("unexpected state %v", d.State())
+				}
+				if d.Get("published_urls.#") != 1 || d.Get("published_urls.0") != "https://www.example.com/" {
+					t.Errorf("unexpected published URLs in state %v", d.State())
+				}
+			},
+		},
+		{
+			name:      "read deleted",
+			operation: "read",
+			id:        "uuid-1",
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if d.Id() != "" {
+					t.Errorf("expected the delivery configuration to be removed from the state, got %s", d.Id())
+				}
+			},
+		},
+		{
+			name:          "read failure",
+			setup:         func(m *Meta) { testCreateFakeDelivery(t, m, "/") },
+			operation:     "read",
+			id:            "uuid-1",
+			failures:      fakeFailures{"GetDelivery": http.StatusInternalServerError},
+			expectedError: "error reading delivery configuration uuid-1",
+		},
+		{
+			name:      "update",
+			setup:     func(m *Meta) { testCreateFakeDelivery(t, m, "/") },
+			operation: "update",
+			id:        "uuid-1",
+			config:    testDeliveryConfig("/v2"),
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if d.Get("source_path") != "/v2" || d.Get("version_number") != 2 || testFakeDeliverySourcePath(m) != "/v2" {
+					t.Errorf("unexpected state %v", d.State())
+				}
+			},
+		},
+		{
+			name:      "rollback",
+			setup:     func(m *Meta) { testCreateFakeDelivery(t, m, "/", "/v2") },
+			operation: "update",
+			id:        "uuid-1",
+			config: func() map[string]interface{} {
+				config := testDeliveryConfig("/v2")
+				config["rollback_to_revision"] = 1
+				return config
+			}(),
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if d.Get("source_path") != "/" || d.Get("version_number") != 3 || testFakeDeliverySourcePath(m) != "/" {
+					t.Errorf("unexpected state %v", d.State())
+				}
+			},
+		},
+		{
+			name:          "create violating policy",
+			setup:         func(m *Meta) { m.Policy = &deliveryPolicy{AllowedSourceHostnamePatterns: []string{"*.example.net"}} },
+			operation:     "create",
+			config:        testDeliveryConfig("/"),
+			expectedError: "allowed_source_hostname_patterns",
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if testFakeDeliverySourcePath(m) != nil {
+					t.Error("expected no delivery configuration to be created")
+				}
+			},
+		},
+		{
+			name: "rollback violating policy",
+			setup: func(m *Meta) {
+				testCreateFakeDelivery(t, m, "/", "/v2")
+				m.Policy = &deliveryPolicy{AllowedServiceProfiles: []string{"LLNW-Custom"}}
+			},
+			operation: "update",
+			id:        "uuid-1",
+			config: func() map[string]interface{} {
+				config := testDeliveryConfig("/v2")
+				config["rollback_to_revision"] = 1
+				return config
+			}(),
+			expectedError: "cannot roll back to revision 1",
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if testFakeDeliverySourcePath(m) != "/v2" {
+					t.Errorf("expected the delivery configuration to be unchanged, got %v", testFakeDeliverySourcePath(m))
+				}
+			},
+		},
+		{
+			name:          "update failure",
+			setup:         func(m *Meta) { testCreateFakeDelivery(t, m, "/") },
+			operation:     "update",
+			id:            "uuid-1",
+			config:        testDeliveryConfig("/v2"),
+			failures:      fakeFailures{"UpdateDelivery": http.StatusBadRequest},
+			expectedError: "error updating delivery configuration",
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if testFakeDeliverySourcePath(m) != "/" {
+					t.Errorf("expected the delivery configuration to be unchanged, got %v", testFakeDeliverySourcePath(m))
+				}
+			},
+		},
+		{
+			name:      "delete",
+			setup:     func(m *Meta) { testCreateFakeDelivery(t, m, "/") },
+			operation: "delete",
+			id:        "uuid-1",
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if testFakeDeliverySourcePath(m) != nil {
+					t.Error("expected the delivery configuration to be deleted")
+				}
+			},
+		},
+		{
+			name:      "delete already deleted",
+			setup:     func(m *Meta) { testCreateFakeDelivery(t, m, "/") },
+			operation: "delete",
+			id:        "uuid-1",
+			failures:  fakeFailures{"DeleteDelivery": http.StatusNotFound},
+		},
+		{
+			name:          "delete protected",
+			setup:         func(m *Meta) { testCreateFakeDelivery(t, m, "/") },
+			operation:     "delete",
+			id:            "uuid-1",
+			state:         map[string]string{"deletion_protection": "true"},
+			expectedError: "deletion_protection",
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if testFakeDeliverySourcePath(m) == nil {
+					t.Error("expected the delivery configuration not to be deleted")
+				}
+			},
+		},
+		{
+			name:          "delete in use",
+			setup:         func(m *Meta) { testCreateFakeDelivery(t, m, "/") },
+			operation:     "delete",
+			id:            "uuid-1",
+			failures:      fakeFailures{"DeleteDelivery": http.StatusConflict},
+			expectedError: "still in use",
+		},
+	})
+}
+
 func testAccLimelightDeliveryCheckDestroy(state *terraform.State) error {
-	client := getConfigurationClient(testAccProvider.Meta().(map[string]interface{}))
+	client := getConfigurationClient(testAccProvider.Meta())
 	for _, rs := range state.RootModule().Resources {
 
 		if rs.Type != "limelight_delivery" {
@@ -125,7 +627,7 @@ func testAccLimelightDeliveryCheckDestroy(state *terraform.State) error {
 		}
 
 		resourceID := rs.Primary.Attributes["id"]
-		_, resp, err := client.GetDeliveryServiceInstance(resourceID)
+		_, resp, err := getServiceInstance(client, deliveryServiceKey, resourceID)
 
 		if err != nil {
 			if resp != nil && resp.StatusCode == http.StatusNotFound {
@@ -141,7 +643,7 @@ func testAccLimelightDeliveryCheckDestroy(state *terraform.State) error {
 func testAccLimelightDeliveryExists(testResourceName string) resource.TestCheckFunc {
 	return func(state *terraform.State) error {
 
-		client := getConfigurationClient(testAccProvider.Meta().(map[string]interface{}))
+		client := getConfigurationClient(testAccProvider.Meta())
 
 		rs, ok := state.RootModule().Resources[testResourceName]
 		if !ok {
@@ -153,7 +655,7 @@ func testAccLimelightDeliveryExists(testResourceName string) resource.TestCheckF
 			return fmt.Errorf("delivery configuration ID not set in resources ")
 		}
 
-		serviceInst, resp, err := client.GetDeliveryServiceInstance(resourceID)
+		serviceInst, resp, err := getServiceInstance(client, deliveryServiceKey, resourceID)
 		if err != nil {
 			return fmt.Errorf("error retrieving delivery configuration for %s: %s", resourceID, err)
 		}
@@ -258,3 +760,40 @@ resource "limelight_delivery" "test_delivery" {
 	}
 }`, getShortname(), getShortname())
 }
+
+func testAccLimelightDeliveryProviderDefaultsTemplate() string {
+	return fmt.Sprintf(`
+provider "limelight" {
+	default_shortname       = "%s"
+	default_service_profile = "LLNW-Generic"
+}
+
+resource "limelight_delivery" "test_delivery" {
+	published_hostname = "terraform-test-defaults.%s.s.llnwi.net"
+	published_path     = "/"
+	source_hostname    = "dummy-origin-defaults.llnw.net"
+	source_path        = "/"
+	
+	protocol_set {
+		published_protocol = "https"
+		source_protocol    = "https"
+	}
+}`, getShortname(), getShortname())
+}
+
+func testAccLimelightDeliveryDeletionProtectionTemplate(deletionProtection bool) string {
+	return fmt.Sprintf(`
+resource "limelight_delivery" "test_delivery" {
+	shortname           = "%s"
+	published_hostname  = "terraform-test-protection.%s.s.llnwi.net"
+	published_path      = "/"
+	source_hostname     = "dummy-origin-protection.llnw.net"
+	source_path         = "/"
+	deletion_protection = %t
+	
+	protocol_set {
+		published_protocol = "https"
+		source_protocol    = "https"
+	}
+}`, getShortname(), getShortname(), deletionProtection)
+}
@@ -1,13 +1,18 @@
 package limelight
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 
+	"github.com/hashicorp/terraform-plugin-sdk/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/llnw/llnw-sdk-go/edgefunctions"
 )
@@ -21,11 +26,23 @@ func resourceLimelightEdgeFunction() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		CustomizeDiff: customdiff.Sequence(
+			customizeDiffDefaultShortname,
+			customizeDiffDeletionProtection("shortname", "name"),
+			customizeDiffEdgeFunctionArchive,
+		),
 		Schema: map[string]*schema.Schema{
 			"shortname": &schema.Schema{
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				DefaultFunc: providerDefaultFunc,
+				ForceNew:    true,
 			},
 			"name": &schema.Schema{
 				Type:     schema.TypeString,
@@ -37,8 +54,9 @@ func resourceLimelightEdgeFunction() *schema.Resource {
 				Optional: true,
 			},
 			"function_archive": &schema.Schema{
-				Type:     schema.TypeString,
-				Required: true,
+				Type:             schema.TypeString,
+				Required:         true,
+				DiffSuppressFunc: suppressFunctionArchiveDiff,
 			},
 			"handler": &schema.Schema{
 				Type:     schema.TypeString,
@@ -82,14 +100,18 @@ func resourceLimelightEdgeFunction() *schema.Resource {
 				Optional:     true,
 				ValidateFunc: validation.IntAtLeast(0),
 			},
+			"deletion_protection": deletionProtectionSchema(),
 		},
 	}
 }
 
 func resourceLimelightEdgeFunctionCreate(d *schema.ResourceData, m interface{}) error {
-	c := getEdgeFunctionsClient(m)
+	c := getMeta(m).EdgeFunctions
 
-	shortname := d.Get("shortname").(string)
+	shortname, err := resolveShortname(d, m)
+	if err != nil {
+		return err
+	}
 	name := d.Get("name").(string)
 	description := d.Get("description").(string)
 	functionArchive := d.Get("function_archive").(string)
@@ -101,12 +123,16 @@ func resourceLimelightEdgeFunctionCreate(d *schema.ResourceData, m interface{})
 	environmentVariables := expandEnvVars(d.Get("environment_variable").(*schema.Set))
 	concurrency := d.Get("reserved_concurrency").(int)
 
-	zipFile, err := loadZipFile(functionArchive)
+	zipFile, err := loadZipFile(functionArchive, d.Get("function_sha256").(string), getMeta(m).Transport)
 
 	if err != nil {
 		return err
 	}
 
+	if err := validateFunctionArchive(zipFile, handler, runtime); err != nil {
+		return err
+	}
+
 	edgeFunction := &edgefunctions.EdgeFunction{
 		Name:                 name,
 		Description:          description,
@@ -119,20 +145,31 @@ func resourceLimelightEdgeFunctionCreate(d *schema.ResourceData, m interface{})
 		EnvironmentVariables: environmentVariables,
 	}
 
+	// The upload and setting the concurrency share the create timeout.
+	deadline := time.Now().Add(d.Timeout(schema.TimeoutCreate))
+
 	log.Printf("[INFO] Creating EdgeFunction: %s", name)
-	edgeFunctionResponse, _, err := c.CreateEdgeFunction(shortname, edgeFunction)
+	var edgeFunctionResponse *edgefunctions.EdgeFunction
+	err = retryAPICreate(time.Until(deadline), func(remaining time.Duration) (resp *http.Response, err error) {
+		edgeFunctionResponse, resp, err = c.CreateEdgeFunction(shortname, edgeFunction, remaining)
+		return resp, err
+	})
 
 	if err != nil {
 		return fmt.Errorf("error creating EdgeFunction: %s", err)
 	}
 
+	keepDeployedArchive(shortname, name, edgeFunction.FunctionArchive)
+
 	if concurrency > 0 {
 		log.Printf("[INFO] Setting EdgeFunction concurrency to: %v", concurrency)
-		_, err := c.SetEdgeFunctionConcurrency(name, shortname, concurrency)
+		err := retryAPICall(time.Until(deadline), func(remaining time.Duration) (*http.Response, error) {
+			return c.SetEdgeFunctionConcurrency(name, shortname, concurrency, remaining)
+		})
 
 		if err != nil {
 			log.Printf("[WARN] Failed to set EdgeFunction currency for %s, rolling back EdgeFunction creation", name)
-			_, delErr := c.DeleteEdgeFunction(name, shortname)
+			_, delErr := c.DeleteEdgeFunction(name, shortname, d.Timeout(schema.TimeoutDelete))
 			if delErr != nil {
 				log.Printf("[ERROR] Failed to delete EdgeFunction %s due to: %s", name, delErr)
 			}
@@ -146,7 +183,7 @@ func resourceLimelightEdgeFunctionCreate(d *schema.ResourceData, m interface{})
 }
 
 func resourceLimelightEdgeFunctionRead(d *schema.ResourceData, m interface{}) error {
-	c := getEdgeFunctionsClient(m)
+	c := getMeta(m).EdgeFunctions
 
 	shortname, name, err := resourceLimelightEdgeFunctionSplitID(d.Id())
 
@@ -158,12 +195,7 @@ func resourceLimelightEdgeFunctionRead(d *schema.ResourceData, m interface{}) er
 	edgeFunction, resp, err := c.GetEdgeFunction(name, shortname)
 
 	if err != nil {
-		if resp != nil && resp.StatusCode == http.StatusNotFound {
-			log.Printf("[INFO] EdgeFunction %s was not found", name)
-			d.SetId("")
-			return nil
-		}
-		return fmt.Errorf("error reading EdgeFunction: %s", err)
+		return handleReadError(d, resp, err, fmt.Sprintf("EdgeFunction %s", name))
 	}
 
 	d.Set("shortname", shortname)
@@ -178,12 +210,13 @@ func resourceLimelightEdgeFunctionRead(d *schema.ResourceData, m interface{}) er
 	d.Set("environment_variable", flattenEnvVars(edgeFunction.EnvironmentVariables))
 	d.Set("revision_id", edgeFunction.RevisionID)
 	d.Set("reserved_concurrency", edgeFunction.ReservedConcurrency)
+	setDeletionProtectionDefault(d)
 
 	return nil
 }
 
 func resourceLimelightEdgeFunctionUpdate(d *schema.ResourceData, m interface{}) error {
-	c := getEdgeFunctionsClient(m)
+	c := getMeta(m).EdgeFunctions
 
 	shortname, name, err := resourceLimelightEdgeFunctionSplitID(d.Id())
 
@@ -191,24 +224,69 @@ func resourceLimelightEdgeFunctionUpdate(d *schema.ResourceData, m interface{})
 		return err
 	}
 
+	log.Printf("[INFO] Fetching EdgeFunction %s before updating it", name)
+	snapshot, _, err := c.GetEdgeFunction(name, shortname)
+
+	if err != nil {
+		return fmt.Errorf("error reading EdgeFunction before update: %s", err)
+	}
+
+	var completed []edgeFunctionUpdateStep
+
+	// The steps of the update share the update timeout, and reverting them is given the
+	// same time again.
+	updateTimeout := d.Timeout(schema.TimeoutUpdate)
+	deadline := time.Now().Add(updateTimeout)
+
 	d.Partial(true)
 
 	if d.HasChange("function_sha256") {
 		functionArchive := d.Get("function_archive").(string)
-		zipFile, zipErr := loadZipFile(functionArchive)
+		zipFile, zipErr := loadZipFile(functionArchive, d.Get("function_sha256").(string), getMeta(m).Transport)
 
 		if zipErr != nil {
 			return zipErr
 		}
 
+		if err := validateFunctionArchive(zipFile, d.Get("handler").(string), d.Get("runtime").(string)); err != nil {
+			return err
+		}
+
+		// The previous archive is read before the new one replaces the kept copy, and is
+		// only needed if the update is reverted.
+		oldArchive, _ := d.GetChange("function_archive")
+		oldSha256, _ := d.GetChange("function_sha256")
+		previousZipFile, previousErr := loadPreviousZipFile(shortname, name, oldArchive.(string), oldSha256.(string), getMeta(m).Transport)
+
 		log.Printf("[INFO] Updating EdgeFunction code for: %s", name)
-		_, _, err := c.UpdateEdgeFunctionCode(name, shortname, zipFile)
+		err := retryAPICall(time.Until(deadline), func(remaining time.Duration) (*http.Response, error) {
+			_, resp, err := c.UpdateEdgeFunctionCode(name, shortname, zipFile, remaining)
+			return resp, err
+		})
 
 		if err != nil {
 			return fmt.Errorf("error updating EdgeFunction code: %s", err)
 		}
-		d.SetPartial("function_archive")
-		d.SetPartial("function_sha256")
+
+		keepDeployedArchive(shortname, name, zipFile)
+
+		completed = append(completed, edgeFunctionUpdateStep{
+			description: "code",
+			partialKeys: []string{"function_archive", "function_sha256"},
+			revert: func() error {
+				if previousErr != nil {
+					return previousErr
+				}
+				err := retryAPICall(updateTimeout, func(remaining time.Duration) (*http.Response, error) {
+					_, resp, err := c.UpdateEdgeFunctionCode(name, shortname, previousZipFile, remaining)
+					return resp, err
+				})
+				if err == nil {
+					keepDeployedArchive(shortname, name, previousZipFile)
+				}
+				return err
+			},
+		})
 	}
 
 	if d.HasChange("description") || d.HasChange("handler") || d.HasChange("runtime") || d.HasChange("memory") || d.HasChange("timeout") || d.HasChange("can_debug") || d.HasChange("environment_variable") {
@@ -231,19 +309,44 @@ func resourceLimelightEdgeFunctionUpdate(d *schema.ResourceData, m interface{})
 		}
 
 		log.Printf("[INFO] Updating EdgeFunction configuration for: %s", name)
-		_, _, err := c.UpdateEdgeFunctionConfiguration(name, shortname, edgeFunction)
+		err := retryAPICall(time.Until(deadline), func(remaining time.Duration) (*http.Response, error) {
+			_, resp, err := c.UpdateEdgeFunctionConfiguration(name, shortname, edgeFunction, remaining)
+			return resp, err
+		})
 
 		if err != nil {
-			return fmt.Errorf("error updating EdgeFunction configuration: %s", err)
+			return rollbackEdgeFunctionUpdate(d, name, fmt.Errorf("error updating EdgeFunction configuration: %s", err), completed)
 		}
+
+		completed = append(completed, edgeFunctionUpdateStep{
+			description: "configuration",
+			partialKeys: []string{"description", "handler", "runtime", "memory", "timeout", "can_debug", "environment_variable"},
+			revert: func() error {
+				previousEdgeFunction := &edgefunctions.EdgeFunction{
+					Description:          snapshot.Description,
+					Handler:              snapshot.Handler,
+					Runtime:              snapshot.Runtime,
+					Memory:               snapshot.Memory,
+					Timeout:              snapshot.Timeout,
+					CanDebug:             snapshot.CanDebug,
+					EnvironmentVariables: snapshot.EnvironmentVariables,
+				}
+				return retryAPICall(updateTimeout, func(remaining time.Duration) (*http.Response, error) {
+					_, resp, err := c.UpdateEdgeFunctionConfiguration(name, shortname, previousEdgeFunction, remaining)
+					return resp, err
+				})
+			},
+		})
 	}
 
 	if d.HasChange("reserved_concurrency") {
 		concurrency := d.Get("reserved_concurrency").(int)
 		log.Printf("[INFO] Updating EdgeFunction reserved concurrency to: %d", concurrency)
-		_, err := c.SetEdgeFunctionConcurrency(name, shortname, concurrency)
+		err := retryAPICall(time.Until(deadline), func(remaining time.Duration) (*http.Response, error) {
+			return c.SetEdgeFunctionConcurrency(name, shortname, concurrency, remaining)
+		})
 		if err != nil {
-			return fmt.Errorf("error updating EdgeFunction concurrency due to: %s", err)
+			return rollbackEdgeFunctionUpdate(d, name, fmt.Errorf("error updating EdgeFunction concurrency due to: %s", err), completed)
 		}
 	}
 
@@ -252,8 +355,49 @@ func resourceLimelightEdgeFunctionUpdate(d *schema.ResourceData, m interface{})
 	return resourceLimelightEdgeFunctionRead(d, m)
 }
 
+// edgeFunctionUpdateStep is a successful step of an EdgeFunction update, which is
+// reverted if a later step fails.
+type edgeFunctionUpdateStep struct {
+	description string
+	partialKeys []string
+	revert      func() error
+}
+
+// rollbackEdgeFunctionUpdate reverts the completed steps of an update in reverse order
+// and returns updateErr annotated with what was and wasn't reverted. The attributes of
+// steps that couldn't be reverted are kept in state, as they were applied.
+func rollbackEdgeFunctionUpdate(d *schema.ResourceData, name string, updateErr error, completed []edgeFunctionUpdateStep) error {
+	var reverted, notReverted []string
+
+	for i := len(completed) - 1; i >= 0; i-- {
+		step := completed[i]
+
+		log.Printf("[WARN] Reverting EdgeFunction %s %s update", name, step.description)
+		if err := step.revert(); err != nil {
+			log.Printf("[ERROR] Failed to revert EdgeFunction %s %s update due to: %s", name, step.description, err)
+			notReverted = append(notReverted, fmt.Sprintf("%s (%s)", step.description, err))
+			for _, key := range step.partialKeys {
+				d.SetPartial(key)
+			}
+			continue
+		}
+
+		reverted = append(reverted, step.description)
+	}
+
+	message := updateErr.Error()
+	if len(reverted) > 0 {
+		message += fmt.Sprintf("; reverted the EdgeFunction %s update of: %s", name, strings.Join(reverted, ", "))
+	}
+	if len(notReverted) > 0 {
+		message += fmt.Sprintf("; failed to revert the EdgeFunction %s update of: %s, which remain updated", name, strings.Join(notReverted, ", "))
+	}
+
+	return errors.New(message)
+}
+
 func resourceLimelightEdgeFunctionDelete(d *schema.ResourceData, m interface{}) error {
-	c := getEdgeFunctionsClient(m)
+	c := getMeta(m).EdgeFunctions
 
 	shortname, name, err := resourceLimelightEdgeFunctionSplitID(d.Id())
 
@@ -261,18 +405,65 @@ func resourceLimelightEdgeFunctionDelete(d *schema.ResourceData, m interface{})
 		return err
 	}
 
+	if err := checkDeletionProtection(d, fmt.Sprintf("EdgeFunction %s", name)); err != nil {
+		return err
+	}
+
 	log.Printf("[INFO] Deleting EdgeFunction: %s", name)
-	_, err = c.DeleteEdgeFunction(name, shortname)
+	var resp *http.Response
+	err = retryAPICall(d.Timeout(schema.TimeoutDelete), func(remaining time.Duration) (*http.Response, error) {
+		var err error
+		resp, err = c.DeleteEdgeFunction(name, shortname, remaining)
+		return resp, err
+	})
+
+	if err := handleDeleteError(resp, err, fmt.Sprintf("EdgeFunction %s", name)); err != nil {
+		return err
+	}
 
+	removeDeployedArchive(shortname, name)
+	return nil
+}
+
+// customizeDiffEdgeFunctionArchive validates the archive of an EdgeFunction that is
+// created, or whose code, handler or runtime is changed, when planning. The archive is
+// validated again before it is uploaded, so archives that are unknown or don't exist yet
+// when planning are skipped.
+func customizeDiffEdgeFunctionArchive(d *schema.ResourceDiff, m interface{}) error {
+	keys := []string{"function_archive", "function_sha256", "handler", "runtime"}
+
+	changed := d.Id() == ""
+	for _, k := range keys {
+		if !d.NewValueKnown(k) {
+			return nil
+		}
+		changed = changed || d.HasChange(k)
+	}
+	if !changed {
+		return nil
+	}
+
+	functionArchive := d.Get("function_archive").(string)
+	zipFile, err := loadZipFile(functionArchive, d.Get("function_sha256").(string), getMeta(m).Transport)
+	if os.IsNotExist(err) {
+		log.Printf("[DEBUG] Not validating EdgeFunction archive %s, which doesn't exist yet", functionArchive)
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("error deleting EdgeFunction: %s", err)
+		return err
 	}
 
-	return nil
+	return validateFunctionArchive(zipFile, d.Get("handler").(string), d.Get("runtime").(string))
 }
 
-func loadZipFile(path string) ([]byte, error) {
-	zipFile, err := ioutil.ReadFile(path)
+// loadZipFile loads an EdgeFunction archive from a local path, or from a URL in which case
+// it is verified against expectedSha256 and downloaded with transport.
+func loadZipFile(location string, expectedSha256 string, transport http.RoundTripper) ([]byte, error) {
+	if isArchiveURL(location) {
+		return fetchArchive(location, expectedSha256, transport)
+	}
+
+	zipFile, err := ioutil.ReadFile(location)
 
 	if err != nil {
 		return nil, err
@@ -281,6 +472,34 @@ func loadZipFile(path string) ([]byte, error) {
 	return zipFile, nil
 }
 
+// suppressFunctionArchiveDiff ignores a change of function_archive while function_sha256
+// stays the same, as the deployed code doesn't change. In particular, the EdgeFunctions
+// API doesn't return the archive, so imported EdgeFunctions have no function_archive.
+func suppressFunctionArchiveDiff(k, old, new string, d *schema.ResourceData) bool {
+	return d.Id() != "" && !d.HasChange("function_sha256")
+}
+
+// loadPreviousZipFile loads the archive an EdgeFunction was last deployed from: the copy
+// kept when it was deployed, or else the archive at location as long as it still has the
+// same content.
+func loadPreviousZipFile(shortname string, name string, location string, expectedSha256 string, transport http.RoundTripper) ([]byte, error) {
+	if zipFile, ok := readDeployedArchive(shortname, name, expectedSha256); ok {
+		return zipFile, nil
+	}
+
+	zipFile, err := loadZipFile(location, expectedSha256, transport)
+
+	if err != nil {
+		return nil, fmt.Errorf("previous archive is no longer available: %s", err)
+	}
+
+	if err := verifyArchiveSha256(zipFile, expectedSha256, location); err != nil {
+		return nil, fmt.Errorf("previous archive has changed since it was deployed: %s", err)
+	}
+
+	return zipFile, nil
+}
+
 func resourceLimelightEdgeFunctionSplitID(id string) (string, string, error) {
 	shortName, fnName, err := splitSeparatedPair(id, ":")
 
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/llnw/llnw-sdk-go/edgefunctions"
 )
@@ -18,11 +20,22 @@ func resourceLimelightEdgeFunctionAlias() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		CustomizeDiff: customdiff.Sequence(
+			customizeDiffDefaultShortname,
+			customizeDiffDeletionProtection("shortname", "name", "function_name"),
+		),
 		Schema: map[string]*schema.Schema{
 			"shortname": &schema.Schema{
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				DefaultFunc: providerDefaultFunc,
+				ForceNew:    true,
 			},
 			"name": &schema.Schema{
 				Type:     schema.TypeString,
@@ -46,14 +59,18 @@ func resourceLimelightEdgeFunctionAlias() *schema.Resource {
 				Type:     schema.TypeInt,
 				Computed: true,
 			},
+			"deletion_protection": deletionProtectionSchema(),
 		},
 	}
 }
 
 func resourceLimelightEdgeFunctionAliasCreate(d *schema.ResourceData, m interface{}) error {
-	client := getEdgeFunctionsClient(m)
+	client := getMeta(m).EdgeFunctions
 
-	shortname := d.Get("shortname").(string)
+	shortname, err := resolveShortname(d, m)
+	if err != nil {
+		return err
+	}
 	name := d.Get("name").(string)
 	fnName := d.Get("function_name").(string)
 	fnVersion := d.Get("function_version").(string)
@@ -66,7 +83,10 @@ func resourceLimelightEdgeFunctionAliasCreate(d *schema.ResourceData, m interfac
 	}
 
 	log.Printf("[INFO] Creating Alias %s for EdgeFunction %s", name, fnName)
-	_, _, err := client.CreateEdgeFunctionAlias(fnName, shortname, alias)
+	err = retryAPICreate(d.Timeout(schema.TimeoutCreate), func(remaining time.Duration) (*http.Response, error) {
+		_, resp, err := client.CreateEdgeFunctionAlias(fnName, shortname, alias, remaining)
+		return resp, err
+	})
 
 	if err != nil {
 		return fmt.Errorf("error creating EdgeFunction Alias %s: %v", name, err)
@@ -78,7 +98,7 @@ func resourceLimelightEdgeFunctionAliasCreate(d *schema.ResourceData, m interfac
 }
 
 func resourceLimelightEdgeFunctionAliasRead(d *schema.ResourceData, m interface{}) error {
-	client := getEdgeFunctionsClient(m)
+	client := getMeta(m).EdgeFunctions
 
 	shortname, fnName, aliasName, err := resourceLimelightEdgeFunctionAliasSplitID(d.Id())
 
@@ -90,12 +110,7 @@ func resourceLimelightEdgeFunctionAliasRead(d *schema.ResourceData, m interface{
 	aliasResponse, resp, err := client.GetEdgeFunctionAlias(fnName, shortname, aliasName)
 
 	if err != nil {
-		if resp != nil && resp.StatusCode == http.StatusNotFound {
-			log.Printf("[INFO] Alias %s for EdgeFunction %s was not found", aliasName, fnName)
-			d.SetId("")
-			return nil
-		}
-		return fmt.Errorf("error reading EdgeFunction Alias: %s", err)
+		return handleReadError(d, resp, err, fmt.Sprintf("Alias %s for EdgeFunction %s", aliasName, fnName))
 	}
 
 	d.Set("shortname", shortname)
@@ -104,12 +119,13 @@ func resourceLimelightEdgeFunctionAliasRead(d *schema.ResourceData, m interface{
 	d.Set("function_name", aliasResponse.Function)
 	d.Set("function_version", aliasResponse.FunctionVersion)
 	d.Set("revision_id", aliasResponse.RevisionID)
+	setDeletionProtectionDefault(d)
 
 	return nil
 }
 
 func resourceLimelightEdgeFunctionAliasUpdate(d *schema.ResourceData, m interface{}) error {
-	client := getEdgeFunctionsClient(m)
+	client := getMeta(m).EdgeFunctions
 
 	shortname := d.Get("shortname").(string)
 	aliasName := d.Get("name").(string)
@@ -125,7 +141,10 @@ func resourceLimelightEdgeFunctionAliasUpdate(d *schema.ResourceData, m interfac
 	}
 
 	log.Printf("[INFO] Updating Alias %s for EdgeFunction %s", aliasName, fnName)
-	_, _, err := client.UpdateEdgeFunctionAlias(fnName, shortname, aliasName, alias)
+	err := retryAPICall(d.Timeout(schema.TimeoutUpdate), func(remaining time.Duration) (*http.Response, error) {
+		_, resp, err := client.UpdateEdgeFunctionAlias(fnName, shortname, aliasName, alias, remaining)
+		return resp, err
+	})
 	if err != nil {
 		return fmt.Errorf("error updating Edge Function Alias %s: %v", aliasName, err)
 	}
@@ -134,7 +153,7 @@ func resourceLimelightEdgeFunctionAliasUpdate(d *schema.ResourceData, m interfac
 }
 
 func resourceLimelightEdgeFunctionAliasDelete(d *schema.ResourceData, m interface{}) error {
-	client := getEdgeFunctionsClient(m)
+	client := getMeta(m).EdgeFunctions
 
 	shortname, fnName, aliasName, err := resourceLimelightEdgeFunctionAliasSplitID(d.Id())
 
@@ -142,13 +161,19 @@ func resourceLimelightEdgeFunctionAliasDelete(d *schema.ResourceData, m interfac
 		return err
 	}
 
-	log.Printf("[INFO] Deleting Alias %s for EdgeFunction %s", aliasName, fnName)
-	_, err = client.DeleteEdgeFunctionAlias(fnName, shortname, aliasName)
-	if err != nil {
-		return fmt.Errorf("failed to delete Edge Function Alias %s due to: %v", aliasName, err)
+	if err := checkDeletionProtection(d, fmt.Sprintf("Alias %s for EdgeFunction %s", aliasName, fnName)); err != nil {
+		return err
 	}
 
-	return nil
+	log.Printf("[INFO] Deleting Alias %s for EdgeFunction %s", aliasName, fnName)
+	var resp *http.Response
+	err = retryAPICall(d.Timeout(schema.TimeoutDelete), func(remaining time.Duration) (*http.Response, error) {
+		var err error
+		resp, err = client.DeleteEdgeFunctionAlias(fnName, shortname, aliasName, remaining)
+		return resp, err
+	})
+
+	return handleDeleteError(resp, err, fmt.Sprintf("Alias %s for EdgeFunction %s", aliasName, fnName))
 }
 
 func resourceLimelightEdgeFunctionAliasSplitID(id string) (string, string, string, error) {
@@ -6,7 +6,9 @@ import (
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/llnw/llnw-sdk-go/edgefunctions"
 )
 
 func TestAccResourceLimelightEdgeFunctionAlias_minimal(t *testing.T) {
@@ -100,8 +102,139 @@ func TestAccResourceLimelightEdgeFunctionAlias_importBasic(t *testing.T) {
 	})
 }
 
+func testEdgeFunctionAliasConfig(fnVersion string) map[string]interface{} {
+	return map[string]interface{}{
+		"shortname":        "example",
+		"name":             "live",
+		"function_name":    "hello",
+		"function_version": fnVersion,
+	}
+}
+
+// testCreateFakeEdgeFunctionAlias adds the EdgeFunction example:hello and, if aliasVersion
+// is set, its alias live to the fake.
+func testCreateFakeEdgeFunctionAlias(t *testing.T, m *Meta, aliasVersion string) {
+	testCreateFakeEdgeFunction(t, m)
+	if aliasVersion != "" {
+		m.EdgeFunctions.CreateEdgeFunctionAlias("hello", "example", &edgefunctions.EdgeFunctionAlias{
+			Name:            "live",
+			FunctionVersion: aliasVersion,
+		}, 0)
+	}
+}
+
+func testFakeEdgeFunctionAlias(m *Meta) *edgefunctions.EdgeFunctionAlias {
+	return m.EdgeFunctions.(*fakeEdgeFunctionAPI).aliases["example:hello:live"]
+}
+
+func TestResourceLimelightEdgeFunctionAliasOperations(t *testing.T) {
+	runResourceTestCases(t, resourceLimelightEdgeFunctionAlias(), []resourceTestCase{
+		{
+			name:      "create",
+			setup:     func(m *Meta) { testCreateFakeEdgeFunctionAlias(t, m, "") },
+			operation: "create",
+			config:    testEdgeFunctionAliasConfig("1"),
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if d.Id() != "example:hello:live" || d.Get("function_version") != "1" || d.Get("revision_id") != 1 {
+					t.Errorf("unexpected state %v", d.State())
+				}
+			},
+		},
+		{
+			name:          "create without function",
+			operation:     "create",
+			config:        testEdgeFunctionAliasConfig("1"),
+			expectedError: "error creating EdgeFunction Alias live",
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if d.Id() != "" {
+					t.Errorf("expected no ID, got %s", d.Id())
+				}
+			},
+		},
+		{
+			name:      "read",
+			setup:     func(m *Meta) { testCreateFakeEdgeFunctionAlias(t, m, "1") },
+			operation: "read",
+			id:        "example:hello:live",
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if d.Get("shortname") != "example" || d.Get("name") != "live" || d.Get("function_name") != "hello" || d.Get("function_version") != "1" {
+					t.Errorf("unexpected state %v", d.State())
+				}
+			},
+		},
+		{
+			name:      "read deleted",
+			setup:     func(m *Meta) { testCreateFakeEdgeFunctionAlias(t, m, "") },
+			operation: "read",
+			id:        "example:hello:live",
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if d.Id() != "" {
+					t.Errorf("expected the alias to be removed from the state, got %s", d.Id())
+				}
+			},
+		},
+		{
+			name:      "update",
+			setup:     func(m *Meta) { testCreateFakeEdgeFunctionAlias(t, m, "1") },
+			operation: "update",
+			id:        "example:hello:live",
+			config:    testEdgeFunctionAliasConfig("2"),
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if d.Get("function_version") != "2" || d.Get("revision_id") != 2 || testFakeEdgeFunctionAlias(m).FunctionVersion != "2" {
+					t.Errorf("unexpected state %v", d.State())
+				}
+			},
+		},
+		{
+			name:          "update conflict",
+			setup:         func(m *Meta) { testCreateFakeEdgeFunctionAlias(t, m, "1") },
+			operation:     "update",
+			id:            "example:hello:live",
+			config:        testEdgeFunctionAliasConfig("2"),
+			failures:      fakeFailures{"UpdateEdgeFunctionAlias": http.StatusPreconditionFailed},
+			expectedError: "error updating Edge Function Alias live",
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if testFakeEdgeFunctionAlias(m).FunctionVersion != "1" {
+					t.Error("expected the alias to be unchanged")
+				}
+			},
+		},
+		{
+			name:      "delete",
+			setup:     func(m *Meta) { testCreateFakeEdgeFunctionAlias(t, m, "1") },
+			operation: "delete",
+			id:        "example:hello:live",
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if testFakeEdgeFunctionAlias(m) != nil {
+					t.Error("expected the alias to be deleted")
+				}
+			},
+		},
+		{
+			name:      "delete already deleted",
+			setup:     func(m *Meta) { testCreateFakeEdgeFunctionAlias(t, m, "1") },
+			operation: "delete",
+			id:        "example:hello:live",
+			failures:  fakeFailures{"DeleteEdgeFunctionAlias": http.StatusNotFound},
+		},
+		{
+			name:          "delete protected",
+			setup:         func(m *Meta) { testCreateFakeEdgeFunctionAlias(t, m, "1") },
+			operation:     "delete",
+			id:            "example:hello:live",
+			state:         map[string]string{"deletion_protection": "true"},
+			expectedError: "deletion_protection",
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if testFakeEdgeFunctionAlias(m) == nil {
+					t.Error("expected the alias not to be deleted")
+				}
+			},
+		},
+	})
+}
+
 func testAccLimelightEdgeFunctionAliasCheckDestroy(state *terraform.State) error {
-	client := getEdgeFunctionsClient(testAccProvider.Meta().(map[string]interface{}))
+	client := getEdgeFunctionsClient(testAccProvider.Meta())
 	for _, rs := range state.RootModule().Resources {
 
 		if rs.Type != "limelight_edgefunction_alias" {
@@ -130,7 +263,7 @@ func testAccLimelightEdgeFunctionAliasCheckDestroy(state *terraform.State) error
 func testAccLimelightEdgeFunctionAliasExists(testResourceName string) resource.TestCheckFunc {
 	return func(state *terraform.State) error {
 
-		client := getEdgeFunctionsClient(testAccProvider.Meta().(map[string]interface{}))
+		client := getEdgeFunctionsClient(testAccProvider.Meta())
 
 		rs, ok := state.RootModule().Resources[testResourceName]
 		if !ok {
@@ -1,12 +1,20 @@
 package limelight
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/llnw/llnw-sdk-go/edgefunctions"
 )
 
 func TestAccResourceLimelightEdgeFunction_minimal(t *testing.T) {
@@ -119,8 +127,332 @@ func TestAccResourceLimelightEdgeFunction_importBasic(t *testing.T) {
 	})
 }
 
+func TestRollbackEdgeFunctionUpdate(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceLimelightEdgeFunction().Schema, map[string]interface{}{})
+
+	var revertedSteps []string
+	completed := []edgeFunctionUpdateStep{
+		{
+			description: "code",
+			revert: func() error {
+				revertedSteps = append(revertedSteps, "code")
+				return nil
+			},
+		},
+		{
+			description: "configuration",
+			revert: func() error {
+				revertedSteps = append(revertedSteps, "configuration")
+				return errors.New("boom")
+			},
+		},
+	}
+
+	err := rollbackEdgeFunctionUpdate(d, "my_function", errors.New("error updating EdgeFunction concurrency"), completed)
+
+	if strings.Join(revertedSteps, ",") != "configuration,code" {
+		t.Errorf("expected steps to be reverted in reverse order, got %v", revertedSteps)
+	}
+	expected := "error updating EdgeFunction concurrency; reverted the EdgeFunction my_function update of: code; " +
+		"failed to revert the EdgeFunction my_function update of: configuration (boom), which remain updated"
+	if err == nil || err.Error() != expected {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+const testEdgeFunctionArchive = "testdata/edgefunc/py_function.zip"
+
+func testEdgeFunctionConfig(t *testing.T, archive string, memory int) map[string]interface{} {
+	zipFile, err := ioutil.ReadFile(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]interface{}{
+		"shortname":        "example",
+		"name":             "hello",
+		"function_archive": archive,
+		"function_sha256":  testSha256(zipFile),
+		"handler":          "hello_world.handler",
+		"runtime":          "python3",
+		"memory":           memory,
+	}
+}
+
+// testCreateFakeEdgeFunction adds the EdgeFunction example:hello to the fake, deployed
+// from testEdgeFunctionArchive.
+func testCreateFakeEdgeFunction(t *testing.T, m *Meta) {
+	zipFile, err := ioutil.ReadFile(testEdgeFunctionArchive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.EdgeFunctions.CreateEdgeFunction("example", &edgefunctions.EdgeFunction{
+		Name:            "hello",
+		FunctionArchive: zipFile,
+		Handler:         "hello_world.handler",
+		Runtime:         "python3",
+		Memory:          256,
+		Timeout:         5000,
+	}, 0)
+}
+
+func testFakeEdgeFunction(m *Meta) *edgefunctions.EdgeFunction {
+	return m.EdgeFunctions.(*fakeEdgeFunctionAPI).functions["example:hello"]
+}
+
+func TestResourceLimelightEdgeFunctionArchiveDiff(t *testing.T) {
+	r := resourceLimelightEdgeFunction()
+	m := newFakeMeta()
+	testCreateFakeEdgeFunction(t, m)
+	imported := testResourceState(t, r, "example:hello", nil, m)
+
+	diff, err := r.Diff(imported, terraform.NewResourceConfigRaw(testEdgeFunctionConfig(t, testEdgeFunctionArchive, 256)), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff != nil && len(diff.Attributes) > 0 {
+		t.Errorf("expected no diff after import with the deployed archive, got %v", diff.Attributes)
+	}
+
+	dir, err := ioutil.TempDir("", "edgefunction")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	newArchive := filepath.Join(dir, "new_function.zip")
+	newCode := testZipArchive(t, map[string]string{"hello_world.py": "def handler(req, context):\n    pass\n"})
+	if err := ioutil.WriteFile(newArchive, newCode, 0644); err != nil {
+		t.Fatal(err)
+	}
+	diff, err = r.Diff(imported, terraform.NewResourceConfigRaw(testEdgeFunctionConfig(t, newArchive, 256)), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff == nil || diff.Attributes["function_archive"] == nil || diff.Attributes["function_sha256"] == nil {
+		t.Errorf("expected function_archive and function_sha256 to change with the code, got %v", diff)
+	}
+}
+
+func TestResourceLimelightEdgeFunctionOperations(t *testing.T) {
+	newCode := testZipArchive(t, map[string]string{"hello_world.py": "def handler(req, context):\n    pass\n"})
+	dir, err := ioutil.TempDir("", "edgefunction")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	newArchive := filepath.Join(dir, "new_function.zip")
+	if err := ioutil.WriteFile(newArchive, newCode, 0644); err != nil {
+		t.Fatal(err)
+	}
+	invalidArchive := filepath.Join(dir, "invalid_function.zip")
+	if err := ioutil.WriteFile(invalidArchive, testZipArchive(t, map[string]string{"main.py": "def handler(req, context):\n    pass\n"}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	deployedFrom := map[string]string{"function_archive": testEdgeFunctionArchive}
+
+	os.Setenv("LLNW_ARCHIVE_CACHE_DIR", filepath.Join(dir, "cache"))
+	defer os.Unsetenv("LLNW_ARCHIVE_CACHE_DIR")
+	originalCode, err := ioutil.ReadFile(testEdgeFunctionArchive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The archive the EdgeFunction was deployed from, since overwritten with the new code.
+	overwrittenArchive := filepath.Join(dir, "function.zip")
+	if err := ioutil.WriteFile(overwrittenArchive, newCode, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runResourceTestCases(t, resourceLimelightEdgeFunction(), []resourceTestCase{
+		{
+			name:      "create",
+			operation: "create",
+			config:    testEdgeFunctionConfig(t, testEdgeFunctionArchive, 256),
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if d.Id() != "example:hello" || d.Get("revision_id") != 1 || d.Get("memory") != 256 {
+					t.Errorf("unexpected state %v", d.State())
+				}
+				if calls := m.EdgeFunctions.(*fakeEdgeFunctionAPI).calls; strings.Join(calls, ",") != "CreateEdgeFunction,GetEdgeFunction" {
+					t.Errorf("unexpected calls %v", calls)
+				}
+			},
+		},
+		{
+			name:          "create with invalid archive",
+			operation:     "create",
+			config:        testEdgeFunctionConfig(t, invalidArchive, 256),
+			expectedError: "handler module hello_world not found in function_archive",
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if d.Id() != "" || len(m.EdgeFunctions.(*fakeEdgeFunctionAPI).calls) != 0 {
+					t.Error("expected no EdgeFunction to be created")
+				}
+			},
+		},
+		{
+			name:      "create with concurrency",
+			operation: "create",
+			config: func() map[string]interface{} {
+				config := testEdgeFunctionConfig(t, testEdgeFunctionArchive, 256)
+				config["reserved_concurrency"] = 10
+				return config
+			}(),
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if d.Get("reserved_concurrency") != 10 || testFakeEdgeFunction(m).ReservedConcurrency != 10 {
+					t.Errorf("unexpected state %v", d.State())
+				}
+			},
+		},
+		{
+			name:      "create concurrency failure",
+			operation: "create",
+			config: func() map[string]interface{} {
+				config := testEdgeFunctionConfig(t, testEdgeFunctionArchive, 256)
+				config["reserved_concurrency"] = 10
+				return config
+			}(),
+			failures:      fakeFailures{"SetEdgeFunctionConcurrency": http.StatusBadRequest},
+			expectedError: "failed to set EdgeFunction currency",
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if d.Id() != "" || testFakeEdgeFunction(m) != nil {
+					t.Error("expected the EdgeFunction creation to be rolled back")
+				}
+			},
+		},
+		{
+			name:      "read",
+			setup:     func(m *Meta) { testCreateFakeEdgeFunction(t, m) },
+			operation: "read",
+			id:        "example:hello",
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if d.Get("shortname") != "example" || d.Get("name") != "hello" || d.Get("handler") != "hello_world.handler" || d.Get("function_sha256") != testFakeEdgeFunction(m).Sha256 {
+					t.Errorf("unexpected state %v", d.State())
+				}
+			},
+		},
+		{
+			name:      "read deleted",
+			operation: "read",
+			id:        "example:hello",
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if d.Id() != "" {
+					t.Errorf("expected the EdgeFunction to be removed from the state, got %s", d.Id())
+				}
+			},
+		},
+		{
+			name:          "read failure",
+			setup:         func(m *Meta) { testCreateFakeEdgeFunction(t, m) },
+			operation:     "read",
+			id:            "example:hello",
+			failures:      fakeFailures{"GetEdgeFunction": http.StatusInternalServerError},
+			expectedError: "error reading EdgeFunction hello",
+		},
+		{
+			name:      "update configuration",
+			setup:     func(m *Meta) { testCreateFakeEdgeFunction(t, m) },
+			operation: "update",
+			id:        "example:hello",
+			state:     deployedFrom,
+			config:    testEdgeFunctionConfig(t, testEdgeFunctionArchive, 512),
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if d.Get("memory") != 512 || testFakeEdgeFunction(m).Memory != 512 {
+					t.Errorf("unexpected state %v", d.State())
+				}
+				if calls := m.EdgeFunctions.(*fakeEdgeFunctionAPI).calls; strings.Join(calls, ",") != "GetEdgeFunction,UpdateEdgeFunctionConfiguration,GetEdgeFunction" {
+					t.Errorf("unexpected calls %v", calls)
+				}
+			},
+		},
+		{
+			name:      "update code",
+			setup:     func(m *Meta) { testCreateFakeEdgeFunction(t, m) },
+			operation: "update",
+			id:        "example:hello",
+			state:     deployedFrom,
+			config:    testEdgeFunctionConfig(t, newArchive, 256),
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if !bytes.Equal(testFakeEdgeFunction(m).FunctionArchive, newCode) || d.Get("function_sha256") != testSha256(newCode) {
+					t.Errorf("unexpected state %v", d.State())
+				}
+				if _, ok := readDeployedArchive("example", "hello", testSha256(newCode)); !ok {
+					t.Error("expected a copy of the deployed archive to be kept")
+				}
+			},
+		},
+		{
+			name:          "update failure reverts code",
+			setup:         func(m *Meta) { testCreateFakeEdgeFunction(t, m) },
+			operation:     "update",
+			id:            "example:hello",
+			state:         deployedFrom,
+			config:        testEdgeFunctionConfig(t, newArchive, 512),
+			failures:      fakeFailures{"UpdateEdgeFunctionConfiguration": http.StatusBadRequest},
+			expectedError: "reverted the EdgeFunction hello update of: code",
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if fn := testFakeEdgeFunction(m); bytes.Equal(fn.FunctionArchive, newCode) || fn.Memory != 256 {
+					t.Errorf("expected the EdgeFunction to be unchanged, got %v", fn)
+				}
+			},
+		},
+		{
+			name: "update failure reverts code overwritten on disk",
+			setup: func(m *Meta) {
+				testCreateFakeEdgeFunction(t, m)
+				keepDeployedArchive("example", "hello", originalCode)
+			},
+			operation:     "update",
+			id:            "example:hello",
+			state:         map[string]string{"function_archive": overwrittenArchive},
+			config:        testEdgeFunctionConfig(t, overwrittenArchive, 512),
+			failures:      fakeFailures{"UpdateEdgeFunctionConfiguration": http.StatusBadRequest},
+			expectedError: "reverted the EdgeFunction hello update of: code",
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if !bytes.Equal(testFakeEdgeFunction(m).FunctionArchive, originalCode) {
+					t.Error("expected the EdgeFunction to be reverted to the deployed code")
+				}
+			},
+		},
+		{
+			name: "delete",
+			setup: func(m *Meta) {
+				testCreateFakeEdgeFunction(t, m)
+				keepDeployedArchive("example", "hello", originalCode)
+			},
+			operation: "delete",
+			id:        "example:hello",
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if testFakeEdgeFunction(m) != nil {
+					t.Error("expected the EdgeFunction to be deleted")
+				}
+				if _, err := os.Stat(deployedArchivePath("example", "hello")); !os.IsNotExist(err) {
+					t.Errorf("expected the copy of the deployed archive to be removed, got %v", err)
+				}
+			},
+		},
+		{
+			name:          "delete protected",
+			setup:         func(m *Meta) { testCreateFakeEdgeFunction(t, m) },
+			operation:     "delete",
+			id:            "example:hello",
+			state:         map[string]string{"deletion_protection": "true"},
+			expectedError: "deletion_protection",
+			check: func(t *testing.T, d *schema.ResourceData, m *Meta) {
+				if testFakeEdgeFunction(m) == nil {
+					t.Error("expected the EdgeFunction not to be deleted")
+				}
+			},
+		},
+		{
+			name:          "delete in use",
+			setup:         func(m *Meta) { testCreateFakeEdgeFunction(t, m) },
+			operation:     "delete",
+			id:            "example:hello",
+			failures:      fakeFailures{"DeleteEdgeFunction": http.StatusConflict},
+			expectedError: "still in use",
+		},
+	})
+}
+
 func testAccLimelightEdgeFunctionCheckDestroy(state *terraform.State, fnName string) error {
-	client := getEdgeFunctionsClient(testAccProvider.Meta().(map[string]interface{}))
+	client := getEdgeFunctionsClient(testAccProvider.Meta())
 	for _, rs := range state.RootModule().Resources {
 
 		if rs.Type != "limelight_edgefunction" {
@@ -149,7 +481,7 @@ func testAccLimelightEdgeFunctionCheckDestroy(state *terraform.State, fnName str
 func testAccLimelightEdgeFunctionExists(testResourceName, fnName string) resource.TestCheckFunc {
 	return func(state *terraform.State) error {
 
-		client := getEdgeFunctionsClient(testAccProvider.Meta().(map[string]interface{}))
+		client := getEdgeFunctionsClient(testAccProvider.Meta())
 
 		rs, ok := state.RootModule().Resources[testResourceName]
 		if !ok {
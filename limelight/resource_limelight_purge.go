@@ -0,0 +1,198 @@
+package limelight
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func resourceLimelightPurge() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceLimelightPurgeCreate,
+		Read:   resourceLimelightPurgeRead,
+		Delete: resourceLimelightPurgeDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+		},
+		CustomizeDiff: customizeDiffDefaultShortname,
+		Schema: map[string]*schema.Schema{
+			"shortname": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				DefaultFunc: providerDefaultFunc,
+				ForceNew:    true,
+			},
+			"urls": &schema.Schema{
+				Type:         schema.TypeList,
+				Optional:     true,
+				ForceNew:     true,
+				AtLeastOneOf: []string{"urls", "patterns"},
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"patterns": &schema.Schema{
+				Type:         schema.TypeList,
+				Optional:     true,
+				ForceNew:     true,
+				AtLeastOneOf: []string{"urls", "patterns"},
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"evict": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				ForceNew: true,
+				Default:  false,
+			},
+			"include_query_string": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				ForceNew: true,
+				Default:  false,
+			},
+			"triggers": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				ForceNew: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"wait_for_completion": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				ForceNew: true,
+				Default:  true,
+			},
+			"request_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"status": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceLimelightPurgeCreate(d *schema.ResourceData, m interface{}) error {
+	c := getPurgeClient(m)
+
+	shortname, err := resolveShortname(d, m)
+	if err != nil {
+		return err
+	}
+	evict := d.Get("evict").(bool)
+	includeQueryString := d.Get("include_query_string").(bool)
+
+	request := &purgeRequest{}
+	for _, v := range d.Get("urls").([]interface{}) {
+		request.Patterns = append(request.Patterns, purgePattern{
+			Pattern:            v.(string),
+			Evict:              evict,
+			Exact:              true,
+			IncludeQueryString: includeQueryString,
+		})
+	}
+	for _, v := range d.Get("patterns").([]interface{}) {
+		request.Patterns = append(request.Patterns, purgePattern{
+			Pattern:            v.(string),
+			Evict:              evict,
+			IncludeQueryString: includeQueryString,
+		})
+	}
+
+	log.Printf("[INFO] Submitting purge request for %d URLs and patterns", len(request.Patterns))
+	status, _, err := c.CreatePurgeRequest(shortname, request)
+
+	if err != nil {
+		return fmt.Errorf("error submitting purge request: %s", err)
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", shortname, status.ID))
+
+	if d.Get("wait_for_completion").(bool) {
+		log.Printf("[INFO] Waiting for completion of purge request %s", status.ID)
+		if err := waitForLimelightPurgeCompletion(c, shortname, status.ID, d.Timeout(schema.TimeoutCreate)); err != nil {
+			return err
+		}
+	}
+
+	return resourceLimelightPurgeRead(d, m)
+}
+
+func resourceLimelightPurgeRead(d *schema.ResourceData, m interface{}) error {
+	c := getPurgeClient(m)
+
+	shortname, requestID, err := resourceLimelightPurgeSplitID(d.Id())
+
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Fetching purge request %s", requestID)
+	status, resp, err := c.GetPurgeRequestStatus(shortname, requestID)
+
+	if err != nil {
+		// Purge requests are only kept for a limited time. The purge has happened, so
+		// keep the resource rather than purging again.
+		if isNotFoundError(resp, err) {
+			log.Printf("[INFO] Purge request %s is no longer available", requestID)
+			return nil
+		}
+		return fmt.Errorf("error reading purge request: %s", err)
+	}
+
+	d.Set("shortname", shortname)
+	d.Set("request_id", requestID)
+	d.Set("status", status.State)
+
+	return nil
+}
+
+func resourceLimelightPurgeDelete(d *schema.ResourceData, m interface{}) error {
+	// A purge can't be undone, so deleting only removes it from the state.
+	return nil
+}
+
+func waitForLimelightPurgeCompletion(c *purgeClient, shortname string, requestID string, timeout time.Duration) error {
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{purgeStatePending, purgeStateInProgress},
+		Target:  []string{purgeStateCompleted},
+		Refresh: func() (interface{}, string, error) {
+			status, _, err := c.GetPurgeRequestStatus(shortname, requestID)
+			if err != nil {
+				return nil, "", err
+			}
+			if status.State == purgeStateFailed {
+				return status, status.State, fmt.Errorf("purge request %s failed", requestID)
+			}
+			return status, status.State, nil
+		},
+		Timeout:    timeout,
+		MinTimeout: 5 * time.Second,
+		Delay:      5 * time.Second,
+	}
+	_, err := stateConf.WaitForState()
+	if err != nil {
+		return fmt.Errorf("failed waiting for purge request %s: %v", requestID, err)
+	}
+	return nil
+}
+
+func resourceLimelightPurgeSplitID(id string) (string, string, error) {
+	shortName, requestID, err := splitSeparatedPair(id, ":")
+
+	if err != nil {
+		return "", "", fmt.Errorf("purge ID in unexpected format (expected '<shortname>:<request ID>'): %s", id)
+	}
+
+	return shortName, requestID, nil
+}
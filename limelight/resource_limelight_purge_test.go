@@ -0,0 +1,46 @@
+package limelight
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestAccResourceLimelightPurge_basic(t *testing.T) {
+	testResourceName := "limelight_purge.test_purge"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLimelightPurgeBasicTemplate("1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(testResourceName, "shortname", getShortname()),
+					resource.TestCheckResourceAttrSet(testResourceName, "request_id"),
+					resource.TestCheckResourceAttr(testResourceName, "status", purgeStateCompleted),
+				),
+			},
+			{
+				Config: testAccLimelightPurgeBasicTemplate("2"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet(testResourceName, "request_id"),
+					resource.TestCheckResourceAttr(testResourceName, "triggers.version", "2"),
+				),
+			},
+		},
+	})
+}
+
+func testAccLimelightPurgeBasicTemplate(version string) string {
+	return fmt.Sprintf(`
+resource "limelight_purge" "test_purge" {
+	shortname = "%s"
+	patterns  = ["https://terraform-test-purge.%s.s.llnwi.net/*"]
+
+	triggers = {
+		version = "%s"
+	}
+}`, getShortname(), getShortname(), version)
+}
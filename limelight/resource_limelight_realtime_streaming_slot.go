@@ -3,7 +3,6 @@ package limelight
 import (
 	"fmt"
 	"log"
-	"net/http"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
@@ -135,12 +134,7 @@ func resourceLimelightRealtimeStreamingSlotRead(d *schema.ResourceData, m interf
 	realtimeStreamingSlot, resp, err := c.GetRealtimeStreamingSlot(slotID, shortname)
 
 	if err != nil {
-		if resp != nil && resp.StatusCode == http.StatusNotFound {
-			log.Printf("[INFO] Realtime Streaming Slot %s not found", slotID)
-			d.SetId("")
-			return nil
-		}
-		return fmt.Errorf("error reading Realtime Streaming Slot: %s", err)
+		return handleReadError(d, resp, err, fmt.Sprintf("Realtime Streaming Slot %s", slotID))
 	}
 
 	// NOTE: password does not come back on GET requests
@@ -166,13 +160,9 @@ func resourceLimelightRealtimeStreamingSlotDelete(d *schema.ResourceData, m inte
 	}
 
 	log.Printf("[INFO] Deleting Realtime Streaming Slot %s", slotID)
-	_, err = c.DeleteRealtimeStreamingSlot(slotID, shortname)
+	resp, err := c.DeleteRealtimeStreamingSlot(slotID, shortname)
 
-	if err != nil {
-		return fmt.Errorf("error deleting Realtime Streaming slot: %s", err)
-	}
-
-	return nil
+	return handleDeleteError(resp, err, fmt.Sprintf("Realtime Streaming Slot %s", slotID))
 }
 
 func waitForLimelightRealtimeStreamingSlotProvision(d *schema.ResourceData, m interface{}) error {
@@ -193,7 +183,7 @@ func waitForLimelightRealtimeStreamingSlotProvision(d *schema.ResourceData, m in
 		Refresh: func() (interface{}, string, error) {
 
 			realtimeStreamingSlot, resp, err := client.GetRealtimeStreamingSlot(slotID, shortname)
-			if err != nil && resp != nil && resp.StatusCode == http.StatusNotFound {
+			if isNotFoundError(resp, err) {
 				d.Set("state", "NOT_FOUND")
 				return nil, "NOT_FOUND", err
 			}
@@ -77,7 +77,7 @@ func testAccResourceLimelightRealtimeStreamingSlot_update(t *testing.T) {
 }
 
 func testAccLimelightRealtimeStreamingSlotCheckDestroy(state *terraform.State) error {
-	client := getConfigurationClient(testAccProvider.Meta().(map[string]interface{}))
+	client := getConfigurationClient(testAccProvider.Meta())
 	for _, rs := range state.RootModule().Resources {
 
 		if rs.Type != "limelight_realtime_streaming_slot" {
@@ -107,7 +107,7 @@ func testAccLimelightRealtimeStreamingSlotCheckDestroy(state *terraform.State) e
 func testAccLimelightRealtimeStreamingSlotExists(testResourceName string) resource.TestCheckFunc {
 	return func(state *terraform.State) error {
 
-		client := getConfigurationClient(testAccProvider.Meta().(map[string]interface{}))
+		client := getConfigurationClient(testAccProvider.Meta())
 
 		rs, ok := state.RootModule().Resources[testResourceName]
 		if !ok {
@@ -0,0 +1,213 @@
+package limelight
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/structure"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+func resourceLimelightServiceInstance() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceLimelightServiceInstanceCreate,
+		Read:   resourceLimelightServiceInstanceRead,
+		Update: resourceLimelightServiceInstanceUpdate,
+		Delete: resourceLimelightServiceInstanceDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		CustomizeDiff: customdiff.Sequence(
+			customizeDiffDefaultShortname,
+			customizeDiffServiceInstancePolicy,
+		),
+		Schema: map[string]*schema.Schema{
+			"shortname": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				DefaultFunc: providerDefaultFunc,
+			},
+			"service_key": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"body_json": &schema.Schema{
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateFunc:     validation.StringIsJSON,
+				DiffSuppressFunc: structure.SuppressJsonDiff,
+			},
+			"version_number": &schema.Schema{
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"effective_body_json": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceLimelightServiceInstanceCreate(d *schema.ResourceData, m interface{}) error {
+	c := getConfigurationClient(m)
+
+	shortname, err := resolveShortname(d, m)
+	if err != nil {
+		return err
+	}
+	serviceKey := d.Get("service_key").(string)
+
+	body, err := expandServiceInstanceBody(d.Get("body_json").(string), serviceKey)
+	if err != nil {
+		return err
+	}
+
+	if serviceKey == deliveryServiceKey {
+		if err := checkDeliveryPolicy(getMeta(m).Policy, body); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("[INFO] Creating %s service instance", serviceKey)
+	instance, _, err := createServiceInstance(c, serviceKey, body, shortname, defaultAPIRequestTimeout)
+
+	if err != nil {
+		return fmt.Errorf("error creating %s service instance: %s", serviceKey, err)
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", serviceKey, instance.UUID))
+
+	return resourceLimelightServiceInstanceRead(d, m)
+}
+
+func resourceLimelightServiceInstanceRead(d *schema.ResourceData, m interface{}) error {
+	c := getConfigurationClient(m)
+
+	serviceKey, uuid, err := resourceLimelightServiceInstanceSplitID(d.Id())
+
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Fetching %s service instance: %s", serviceKey, uuid)
+	instance, resp, err := getServiceInstance(c, serviceKey, uuid)
+
+	if err != nil {
+		return handleReadError(d, resp, err, fmt.Sprintf("%s service instance %s", serviceKey, uuid))
+	}
+
+	bodyJSON, err := flattenServiceInstanceBody(instance.Body, d.Get("body_json").(string))
+	if err != nil {
+		return err
+	}
+
+	d.Set("shortname", instance.Shortname)
+	d.Set("service_key", serviceKey)
+	d.Set("body_json", bodyJSON)
+
+	return setServiceInstanceRevision(d, instance)
+}
+
+func resourceLimelightServiceInstanceUpdate(d *schema.ResourceData, m interface{}) error {
+	c := getConfigurationClient(m)
+
+	serviceKey, uuid, err := resourceLimelightServiceInstanceSplitID(d.Id())
+
+	if err != nil {
+		return err
+	}
+
+	shortname, err := resolveShortname(d, m)
+	if err != nil {
+		return err
+	}
+
+	body, err := expandServiceInstanceBody(d.Get("body_json").(string), serviceKey)
+	if err != nil {
+		return err
+	}
+
+	if serviceKey == deliveryServiceKey {
+		if err := checkDeliveryPolicy(getMeta(m).Policy, body); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("[INFO] Updating %s service instance: %s", serviceKey, uuid)
+	_, _, err = updateServiceInstance(c, serviceKey, uuid, body, shortname, defaultAPIRequestTimeout)
+
+	if err != nil {
+		return fmt.Errorf("error updating %s service instance: %s", serviceKey, err)
+	}
+
+	return resourceLimelightServiceInstanceRead(d, m)
+}
+
+func resourceLimelightServiceInstanceDelete(d *schema.ResourceData, m interface{}) error {
+	c := getConfigurationClient(m)
+
+	serviceKey, uuid, err := resourceLimelightServiceInstanceSplitID(d.Id())
+
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Deleting %s service instance: %s", serviceKey, uuid)
+	resp, err := deleteServiceInstance(c, serviceKey, uuid, defaultAPIRequestTimeout)
+
+	return handleDeleteError(resp, err, fmt.Sprintf("%s service instance %s", serviceKey, uuid))
+}
+
+// expandServiceInstanceBody parses body_json, setting the service key the body belongs to
+// unless the body already sets it.
+func expandServiceInstanceBody(bodyJSON string, serviceKey string) (map[string]interface{}, error) {
+	body, err := parseJSONObject(bodyJSON, "body_json")
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeJSONObjects(map[string]interface{}{
+		"serviceKey": map[string]interface{}{
+			"name": serviceKey,
+		},
+	}, body), nil
+}
+
+// flattenServiceInstanceBody returns the fields of the API body that are set in the
+// prior body_json, so that defaults added by the API don't produce a diff but changes
+// to the managed fields do. When there is no prior body_json, e.g. on import, the whole
+// body is returned.
+func flattenServiceInstanceBody(body map[string]interface{}, priorBodyJSON string) (string, error) {
+	flattened := body
+	if priorBodyJSON != "" {
+		prior, err := parseJSONObject(priorBodyJSON, "body_json")
+		if err != nil {
+			return "", err
+		}
+		flattened = projectJSONObject(body, prior)
+	}
+
+	encoded, err := json.Marshal(flattened)
+	if err != nil {
+		return "", fmt.Errorf("error encoding service instance body: %s", err)
+	}
+
+	return string(encoded), nil
+}
+
+func resourceLimelightServiceInstanceSplitID(id string) (string, string, error) {
+	serviceKey, uuid, err := splitSeparatedPair(id, ":")
+
+	if err != nil {
+		return "", "", fmt.Errorf("service instance ID in unexpected format (expected '<service key>:<UUID>'): %s", id)
+	}
+
+	return serviceKey, uuid, nil
+}
@@ -0,0 +1,121 @@
+package limelight
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccResourceLimelightServiceInstance_basic(t *testing.T) {
+	testResourceName := "limelight_service_instance.test_instance"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		CheckDestroy: func(state *terraform.State) error {
+			return testAccLimelightServiceInstanceCheckDestroy(state)
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLimelightServiceInstanceBasicTemplate("/"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccLimelightServiceInstanceExists(testResourceName),
+					resource.TestCheckResourceAttr(testResourceName, "shortname", getShortname()),
+					resource.TestCheckResourceAttr(testResourceName, "service_key", "delivery"),
+					resource.TestCheckResourceAttrSet(testResourceName, "version_number"),
+					resource.TestCheckResourceAttrSet(testResourceName, "effective_body_json"),
+				),
+			},
+			{
+				Config: testAccLimelightServiceInstanceBasicTemplate("/source"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccLimelightServiceInstanceExists(testResourceName),
+					resource.TestCheckResourceAttrSet(testResourceName, "version_number"),
+				),
+			},
+			{
+				ResourceName:            testResourceName,
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"body_json"},
+			},
+		},
+	})
+}
+
+func testAccLimelightServiceInstanceCheckDestroy(state *terraform.State) error {
+	client := getConfigurationClient(testAccProvider.Meta())
+	for _, rs := range state.RootModule().Resources {
+
+		if rs.Type != "limelight_service_instance" {
+			continue
+		}
+
+		serviceKey, uuid, err := resourceLimelightServiceInstanceSplitID(rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+
+		_, resp, err := getServiceInstance(client, serviceKey, uuid)
+
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				return nil
+			}
+			return fmt.Errorf("error retrieving service instance with ID %s. Error: %v", rs.Primary.ID, err)
+		}
+
+	}
+	return fmt.Errorf("service instance still exists")
+}
+
+func testAccLimelightServiceInstanceExists(testResourceName string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+
+		client := getConfigurationClient(testAccProvider.Meta())
+
+		rs, ok := state.RootModule().Resources[testResourceName]
+		if !ok {
+			return fmt.Errorf("service instance %s not found in resources", testResourceName)
+		}
+
+		serviceKey, uuid, err := resourceLimelightServiceInstanceSplitID(rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+
+		instance, _, err := getServiceInstance(client, serviceKey, uuid)
+		if err != nil {
+			return fmt.Errorf("error retrieving service instance for %s: %s", rs.Primary.ID, err)
+		}
+
+		if instance.UUID == uuid {
+			return nil
+		}
+
+		return fmt.Errorf("service instance with ID %s wasn't found", rs.Primary.ID)
+	}
+}
+
+func testAccLimelightServiceInstanceBasicTemplate(sourcePath string) string {
+	return fmt.Sprintf(`
+resource "limelight_service_instance" "test_instance" {
+	shortname   = "%s"
+	service_key = "delivery"
+	body_json   = jsonencode({
+		serviceProfileName = "LLNW-Generic"
+		publishedHostname  = "terraform-test-svcinst.%s.s.llnwi.net"
+		publishedUrlPath   = "/"
+		sourceHostname     = "dummy-origin-svcinst.llnw.net"
+		sourceUrlPath      = "%s"
+		protocolSets = [{
+			publishedProtocol = "https"
+			sourceProtocol    = "https"
+			options           = []
+		}]
+	})
+}`, getShortname(), getShortname(), sourcePath)
+}
@@ -0,0 +1,67 @@
+package limelight
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+// errTimedOutBeforeRequest is returned when the timeout elapsed before a request was sent.
+var errTimedOutBeforeRequest = fmt.Errorf("timed out before the request could be sent")
+
+// retryAPICall calls f until it succeeds, fails in a way that isn't worth retrying, or
+// timeout has elapsed. f is passed the time left, to bound its requests with. Only
+// responses that ask for the request to be sent again are retried: a request that timed
+// out may still have been applied.
+func retryAPICall(timeout time.Duration, f func(remaining time.Duration) (*http.Response, error)) error {
+	return retryAPIRequest(timeout, func(resp *http.Response, err error) bool {
+		return classifyAPIError(resp, err) == apiErrorRetryable
+	}, f)
+}
+
+// retryAPICreate is retryAPICall for requests that create an object. Only 429 responses
+// are retried, as a gateway error doesn't tell whether the object was created, and
+// sending the request again could create a second one.
+func retryAPICreate(timeout time.Duration, f func(remaining time.Duration) (*http.Response, error)) error {
+	return retryAPIRequest(timeout, func(resp *http.Response, err error) bool {
+		return resp != nil && resp.StatusCode == http.StatusTooManyRequests
+	}, f)
+}
+
+func retryAPIRequest(timeout time.Duration, retryable func(*http.Response, error) bool, f func(remaining time.Duration) (*http.Response, error)) error {
+	if timeout <= 0 {
+		return errTimedOutBeforeRequest
+	}
+	deadline := time.Now().Add(timeout)
+
+	var lastErr error
+	return resource.Retry(timeout, func() *resource.RetryError {
+		// resource.Retry tries once more after the timeout, which would leave the request
+		// unbounded.
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			if lastErr == nil {
+				return resource.NonRetryableError(errTimedOutBeforeRequest)
+			}
+			return resource.NonRetryableError(lastErr)
+		}
+
+		resp, err := f(remaining)
+		if err == nil {
+			return nil
+		}
+
+		if retryable(resp, err) {
+			log.Printf("[DEBUG] Retrying after status %d: %s", resp.StatusCode, err)
+			if resp.Body != nil {
+				resp.Body.Close()
+			}
+			lastErr = err
+			return resource.RetryableError(err)
+		}
+		return resource.NonRetryableError(err)
+	})
+}
@@ -0,0 +1,124 @@
+package limelight
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIRequest(t *testing.T) {
+	var principal, contentType, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal = r.Header.Get(headerSecurityPrincipal)
+		contentType = r.Header.Get("Content-Type")
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	client := newAPIClient("user", "00", server.URL)
+	respBody, _, err := client.request(http.MethodPut, server.URL, map[string]int{"memory": 256}, time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if principal != "user" {
+		t.Errorf("expected the request to be signed by user, got %q", principal)
+	}
+	if contentType != "application/json" {
+		t.Errorf("expected a JSON request, got %q", contentType)
+	}
+	if body != `{"memory":256}` {
+		t.Errorf("unexpected request body %s", body)
+	}
+	if string(respBody) != `{"ok":true}` {
+		t.Errorf("unexpected response body %s", respBody)
+	}
+}
+
+func TestAPIRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := newAPIClient("user", "00", server.URL)
+	if _, _, err := client.request(http.MethodDelete, server.URL, nil, 50*time.Millisecond); err == nil {
+		t.Error("expected the request to time out")
+	}
+	if _, _, err := client.request(http.MethodDelete, server.URL, nil, time.Second); err != nil {
+		t.Errorf("expected the request to succeed within its timeout, got %s", err)
+	}
+}
+
+func TestRetryAPICall(t *testing.T) {
+	cases := []struct {
+		name             string
+		create           bool
+		statuses         []int
+		expectedAttempts int
+		expectedError    string
+	}{
+		{"success", false, []int{http.StatusOK}, 1, ""},
+		{"retried", false, []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK}, 3, ""},
+		{"not retried", false, []int{http.StatusBadRequest, http.StatusOK}, 1, "got status 400: invalid"},
+		{"timed out", false, []int{http.StatusServiceUnavailable}, 0, "got status 503"},
+		{"create rate limited", true, []int{http.StatusTooManyRequests, http.StatusOK}, 2, ""},
+		{"create unavailable", true, []int{http.StatusServiceUnavailable, http.StatusOK}, 1, "got status 503"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := c.statuses[len(c.statuses)-1]
+				if attempts < len(c.statuses) {
+					status = c.statuses[attempts]
+				}
+				attempts++
+				w.WriteHeader(status)
+				if status == http.StatusBadRequest {
+					w.Write([]byte("invalid"))
+				}
+			}))
+			defer server.Close()
+
+			client := newAPIClient("user", "00", server.URL)
+			retry := retryAPICall
+			if c.create {
+				retry = retryAPICreate
+			}
+			err := retry(2*time.Second, func(remaining time.Duration) (*http.Response, error) {
+				if remaining <= 0 || remaining > 2*time.Second {
+					t.Errorf("unexpected time left %s", remaining)
+				}
+				_, resp, err := client.request(http.MethodPut, server.URL, nil, remaining)
+				return resp, err
+			})
+
+			if c.expectedError == "" && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+			if c.expectedError != "" && (err == nil || !strings.Contains(err.Error(), c.expectedError)) {
+				t.Errorf("expected an error containing %q, got %v", c.expectedError, err)
+			}
+			if c.expectedAttempts > 0 && attempts != c.expectedAttempts {
+				t.Errorf("expected %d attempts, got %d", c.expectedAttempts, attempts)
+			}
+		})
+	}
+}
+
+func TestRetryAPICallTimedOutBeforeRequest(t *testing.T) {
+	err := retryAPICall(time.Nanosecond, func(remaining time.Duration) (*http.Response, error) {
+		t.Errorf("unexpected request with %s left", remaining)
+		return nil, nil
+	})
+	if err == nil {
+		t.Error("expected a timeout error")
+	}
+}
@@ -0,0 +1,236 @@
+package limelight
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/logging"
+)
+
+const (
+	redacted = "[REDACTED]"
+
+	// maxLoggedBodySize is the largest body that is logged in full; longer bodies are
+	// truncated.
+	maxLoggedBodySize = 16 * 1024
+)
+
+// redactedHeaders are never logged. Header names are in canonical form.
+var redactedHeaders = map[string]bool{
+	"Authorization":         true,
+	"Cookie":                true,
+	"Set-Cookie":            true,
+	"X-Llnw-Security-Token": true,
+}
+
+// redactedFields are JSON fields and query parameters whose values are never logged,
+// compared after lower-casing and removing underscores.
+var redactedFields = map[string]bool{
+	"apikey":              true,
+	"password":            true,
+	"secret":              true,
+	"secretkey":           true,
+	"mediavaultsecretkey": true,
+	"token":               true,
+}
+
+// loggingTransport logs API requests and responses at TF_LOG=DEBUG, with credentials
+// and secrets redacted and EdgeFunction archives summarized.
+type loggingTransport struct {
+	transport http.RoundTripper
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !logging.IsDebugOrHigher() {
+		return t.transport.RoundTrip(req)
+	}
+
+	var reqBody []byte
+	if req.Body != nil && req.GetBody != nil {
+		if rc, err := req.GetBody(); err == nil {
+			reqBody, _ = ioutil.ReadAll(rc)
+			rc.Close()
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.transport.RoundTrip(req)
+	latency := time.Since(start)
+
+	if err != nil {
+		log.Printf("[DEBUG] Limelight API request failed after %s:\n%s %s\n%s\n%s\nError: %s",
+			latency, req.Method, redactURL(req.URL), formatHeaders(req.Header), formatBody(reqBody), err)
+		return resp, err
+	}
+
+	respBody, readErr := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+	if readErr != nil {
+		log.Printf("[WARN] Failed to read Limelight API response body for logging: %s", readErr)
+	}
+
+	log.Printf("[DEBUG] Limelight API request (%s, status %d, latency %s%s):\n"+
+		"---[ REQUEST ]---------------------------------------\n%s %s\n%s\n%s\n"+
+		"---[ RESPONSE ]--------------------------------------\n%s\n%s\n%s\n"+
+		"-----------------------------------------------------",
+		req.Method, resp.StatusCode, latency, formatRequestIDs(resp.Header),
+		req.Method, redactURL(req.URL), formatHeaders(req.Header), formatBody(reqBody),
+		resp.Status, formatHeaders(resp.Header), formatBody(respBody))
+
+	return resp, nil
+}
+
+func isRedactedField(name string) bool {
+	return redactedFields[strings.Replace(strings.ToLower(name), "_", "", -1)]
+}
+
+func redactURL(u *url.URL) string {
+	query := u.Query()
+	if len(query) == 0 {
+		return u.String()
+	}
+
+	for k := range query {
+		if isRedactedField(k) {
+			query.Set(k, redacted)
+		}
+	}
+
+	redactedURL := *u
+	redactedURL.RawQuery = query.Encode()
+	return redactedURL.String()
+}
+
+func formatHeaders(headers http.Header) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, len(names))
+	for i, name := range names {
+		value := strings.Join(headers[name], ", ")
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			value = redacted
+		}
+		lines[i] = fmt.Sprintf("%s: %s", name, value)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// formatRequestIDs returns any request ID headers in the response, for quoting to
+// Limelight support.
+func formatRequestIDs(headers http.Header) string {
+	var ids []string
+	for name, values := range headers {
+		normalized := strings.Replace(strings.ToLower(name), "-", "", -1)
+		if strings.HasSuffix(normalized, "requestid") || strings.HasSuffix(normalized, "traceid") {
+			ids = append(ids, fmt.Sprintf("%s %s", name, strings.Join(values, ", ")))
+		}
+	}
+	if len(ids) == 0 {
+		return ""
+	}
+
+	sort.Strings(ids)
+	return ", " + strings.Join(ids, ", ")
+}
+
+func formatBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	if !utf8.Valid(body) {
+		return fmt.Sprintf("[%d bytes of binary data omitted]", len(body))
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err == nil {
+		if redactedBody, err := json.MarshalIndent(redactJSON(v), "", " "); err == nil {
+			body = redactedBody
+		}
+	}
+
+	if len(body) > maxLoggedBodySize {
+		return fmt.Sprintf("%s\n[... %d more bytes truncated]", body[:maxLoggedBodySize], len(body)-maxLoggedBodySize)
+	}
+
+	return string(body)
+}
+
+// redactJSON returns a copy of a decoded JSON value with secret fields redacted,
+// environment variable values redacted and EdgeFunction archives summarized.
+func redactJSON(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		redactedValue := make(map[string]interface{}, len(value))
+		for k, field := range value {
+			switch {
+			case isRedactedField(k):
+				redactedValue[k] = redacted
+			case k == "functionArchive":
+				redactedValue[k] = summarizeArchive(field)
+			case k == "environmentVariables":
+				redactedValue[k] = redactEnvironmentVariables(field)
+			default:
+				redactedValue[k] = redactJSON(field)
+			}
+		}
+		return redactedValue
+	case []interface{}:
+		redactedValue := make([]interface{}, len(value))
+		for i, item := range value {
+			redactedValue[i] = redactJSON(item)
+		}
+		return redactedValue
+	default:
+		return v
+	}
+}
+
+func summarizeArchive(v interface{}) interface{} {
+	archive, ok := v.(string)
+	if !ok {
+		return v
+	}
+	return fmt.Sprintf("[%d bytes of base64 encoded archive omitted]", len(archive))
+}
+
+func redactEnvironmentVariables(v interface{}) interface{} {
+	envVars, ok := v.([]interface{})
+	if !ok {
+		return redactJSON(v)
+	}
+
+	redactedValue := make([]interface{}, len(envVars))
+	for i, envVar := range envVars {
+		rawEnvVar, ok := envVar.(map[string]interface{})
+		if !ok {
+			redactedValue[i] = redacted
+			continue
+		}
+		redactedEnvVar := make(map[string]interface{}, len(rawEnvVar))
+		for k, field := range rawEnvVar {
+			redactedEnvVar[k] = field
+		}
+		if _, ok := redactedEnvVar["value"]; ok {
+			redactedEnvVar["value"] = redacted
+		}
+		redactedValue[i] = redactedEnvVar
+	}
+
+	return redactedValue
+}
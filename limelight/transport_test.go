@@ -0,0 +1,62 @@
+package limelight
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestFormatBody_redactsSecrets(t *testing.T) {
+	body := `{
+		"name": "my_function",
+		"functionArchive": "UEsDBAoAAAAAAA==",
+		"environmentVariables": [{"name": "DB_PASSWORD", "value": "hunter2"}],
+		"mediaVaultSecretKey": "mv-secret",
+		"api_key": "0123abcd",
+		"nested": {"password": "pw"}
+	}`
+
+	formatted := formatBody([]byte(body))
+
+	for _, secret := range []string{"UEsDBAoAAAAAAA==", "hunter2", "mv-secret", "0123abcd", `"pw"`} {
+		if strings.Contains(formatted, secret) {
+			t.Errorf("expected %q to be redacted from:\n%s", secret, formatted)
+		}
+	}
+	for _, kept := range []string{"my_function", "DB_PASSWORD", "bytes of base64 encoded archive omitted"} {
+		if !strings.Contains(formatted, kept) {
+			t.Errorf("expected %q in:\n%s", kept, formatted)
+		}
+	}
+}
+
+func TestFormatBody_truncatesLargeBodies(t *testing.T) {
+	formatted := formatBody([]byte(strings.Repeat("a", maxLoggedBodySize+10)))
+
+	if !strings.HasSuffix(formatted, "[... 10 more bytes truncated]") {
+		t.Errorf("expected body to be truncated, got suffix %q", formatted[len(formatted)-40:])
+	}
+}
+
+func TestFormatHeaders_redactsSignature(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("X-LLNW-Security-Principal", "user")
+	headers.Set("X-LLNW-Security-Token", "signature")
+
+	formatted := formatHeaders(headers)
+
+	if strings.Contains(formatted, "signature") || !strings.Contains(formatted, "user") {
+		t.Errorf("unexpected headers:\n%s", formatted)
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	u, _ := url.Parse("https://apis.llnw.com/config-api/v1/svcinst?api_key=secret&shortname=test")
+
+	redactedURL := redactURL(u)
+
+	if strings.Contains(redactedURL, "secret") || !strings.Contains(redactedURL, "shortname=test") {
+		t.Errorf("unexpected URL %s", redactedURL)
+	}
+}
@@ -0,0 +1,158 @@
+package limelight
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	usageReportFormatCSV  = "csv"
+	usageReportFormatJSON = "json"
+)
+
+var usageReportFormats = []string{usageReportFormatCSV, usageReportFormatJSON}
+
+// UsageReportOptions configures ExportUsageReport.
+type UsageReportOptions struct {
+	Shortname          string
+	Start              time.Time
+	End                time.Time
+	PublishedHostnames []string
+	// OutputFile is written instead of the writer passed to ExportUsageReport, if set.
+	OutputFile string
+	// Format is csv or json. It defaults to json for an OutputFile with a .json extension,
+	// and to csv otherwise.
+	Format string
+}
+
+// ExportUsageReport writes the traffic of each published hostname of an account over a
+// time range to w or to options.OutputFile, as CSV or JSON. The provider is configured
+// from the environment, as it is for Terraform.
+func ExportUsageReport(options UsageReportOptions, w io.Writer) error {
+	if options.Shortname == "" {
+		return fmt.Errorf("shortname must be set")
+	}
+	if !options.Start.Before(options.End) {
+		return fmt.Errorf("the start of the time range must be before its end")
+	}
+
+	provider, err := configureFromEnvironment()
+	if err != nil {
+		return err
+	}
+
+	report, _, err := getReportingClient(provider.Meta()).GetUsageReport(options.Shortname, usageReportQuery{
+		Start:              options.Start,
+		End:                options.End,
+		PublishedHostnames: options.PublishedHostnames,
+	})
+	if err != nil {
+		return fmt.Errorf("error reading usage report: %s", err)
+	}
+
+	format := usageReportFormat(options.Format, options.OutputFile)
+	if options.OutputFile != "" {
+		return writeUsageReportFile(options.OutputFile, format, report)
+	}
+	return writeUsageReport(w, format, report)
+}
+
+// usageReportFormat returns the format of a report file, from its extension unless the
+// format is given.
+func usageReportFormat(format string, path string) string {
+	if format != "" {
+		return format
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return usageReportFormatJSON
+	}
+	return usageReportFormatCSV
+}
+
+func writeUsageReportFile(path string, format string, report *usageReport) error {
+	var b bytes.Buffer
+	if err := writeUsageReport(&b, format, report); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, b.Bytes(), 0644); err != nil {
+		return fmt.Errorf("error writing usage report to %s: %s", path, err)
+	}
+	return nil
+}
+
+func writeUsageReport(w io.Writer, format string, report *usageReport) error {
+	switch format {
+	case usageReportFormatCSV:
+		return writeUsageReportCSV(w, report)
+	case usageReportFormatJSON:
+		return writeUsageReportJSON(w, report)
+	}
+	return fmt.Errorf("unsupported usage report format %q, expected one of %s", format, strings.Join(usageReportFormats, ", "))
+}
+
+// writeUsageReportCSV writes a row per published hostname, with a status_<code> column for
+// each status code in the report.
+func writeUsageReportCSV(w io.Writer, report *usageReport) error {
+	codes := make([]string, 0, len(report.total().StatusCodes))
+	for code := range report.total().StatusCodes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	header := []string{"published_hostname", "bytes", "requests", "cache_hit_ratio"}
+	for _, code := range codes {
+		header = append(header, "status_"+code)
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Write(header)
+	for _, row := range report.Results {
+		record := []string{
+			row.PublishedHostname,
+			strconv.FormatInt(row.Bytes, 10),
+			strconv.FormatInt(row.Requests, 10),
+			strconv.FormatFloat(row.cacheHitRatio(), 'f', -1, 64),
+		}
+		for _, code := range codes {
+			record = append(record, strconv.FormatInt(row.StatusCodes[code], 10))
+		}
+		writer.Write(record)
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
+
+type usageReportJSONRow struct {
+	PublishedHostname string           `json:"published_hostname"`
+	Bytes             int64            `json:"bytes"`
+	Requests          int64            `json:"requests"`
+	CacheHitRatio     float64          `json:"cache_hit_ratio"`
+	StatusCodes       map[string]int64 `json:"status_codes"`
+}
+
+func writeUsageReportJSON(w io.Writer, report *usageReport) error {
+	rows := make([]usageReportJSONRow, len(report.Results))
+	for i, row := range report.Results {
+		rows[i] = usageReportJSONRow{
+			PublishedHostname: row.PublishedHostname,
+			Bytes:             row.Bytes,
+			Requests:          row.Requests,
+			CacheHitRatio:     row.cacheHitRatio(),
+			StatusCodes:       row.StatusCodes,
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(rows)
+}
@@ -3,6 +3,7 @@ package limelight
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 func splitSeparatedTriple(str, separator string) (string, string, string, error) {
@@ -24,3 +25,39 @@ func splitSeparatedPair(str, separator string) (string, string, error) {
 
 	return s[0], s[1], nil
 }
+
+// validatePositiveDuration is a SchemaValidateFunc for durations such as "5m".
+func validatePositiveDuration(i interface{}, k string) (warnings []string, errors []error) {
+	v, ok := i.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %q to be string", k)}
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return nil, []error{fmt.Errorf("expected %q to be a duration such as \"5m\", got %q", k, v)}
+	}
+	if d <= 0 {
+		return nil, []error{fmt.Errorf("expected %q to be positive, got %q", k, v)}
+	}
+	return nil, nil
+}
+
+func expandStringList(flattened []interface{}) []string {
+	expanded := make([]string, 0, len(flattened))
+	for _, v := range flattened {
+		if s, ok := v.(string); ok {
+			expanded = append(expanded, s)
+		}
+	}
+	return expanded
+}
+
+func stringInList(s string, list []string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -1,11 +1,138 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
 	"github.com/hashicorp/terraform-plugin-sdk/plugin"
 	"github.com/llnw/terraform-provider-limelight/limelight"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "export":
+			os.Exit(export(os.Args[2:]))
+		case "logs":
+			os.Exit(logs(os.Args[2:]))
+		case "usage-report":
+			os.Exit(usageReport(os.Args[2:]))
+		}
+	}
+
 	plugin.Serve(&plugin.ServeOpts{
 		ProviderFunc: limelight.Provider})
 }
+
+// export runs the export subcommand, which writes Terraform configuration and imports
+// for an existing account. Credentials and API URLs are read from the same environment
+// variables as the provider's arguments.
+func export(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s export [options]\n\nOptions:\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	shortname := flags.String("shortname", os.Getenv("LLNW_DEFAULT_SHORTNAME"), "account `shortname` to export (defaults to LLNW_DEFAULT_SHORTNAME)")
+	outputDir := flags.String("output-dir", ".", "`directory` to write the configuration and imports to")
+	importBlocks := flags.Bool("import-blocks", false, "also write import blocks to imports.tf, which requires Terraform 1.5 or later")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := limelight.Export(*shortname, *outputDir, *importBlocks); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// logs runs the logs subcommand, which prints or tails the log entries of an EdgeFunction.
+func logs(args []string) int {
+	flags := flag.NewFlagSet("logs", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s logs [options] FUNCTION\n\nOptions:\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	options := limelight.TailOptions{}
+	flags.StringVar(&options.Shortname, "shortname", os.Getenv("LLNW_DEFAULT_SHORTNAME"), "account `shortname` of the function (defaults to LLNW_DEFAULT_SHORTNAME)")
+	flags.StringVar(&options.Alias, "alias", "", "only show entries for this `alias`")
+	flags.StringVar(&options.RequestID, "request-id", "", "only show entries for this request `ID`")
+	flags.StringVar(&options.Level, "level", "", "only show entries of this `level` (DEBUG, INFO, WARN or ERROR)")
+	flags.DurationVar(&options.Since, "since", 10*time.Minute, "show entries from this `duration` ago")
+	flags.BoolVar(&options.Follow, "follow", false, "keep polling for new entries")
+	flags.DurationVar(&options.Interval, "interval", 5*time.Second, "polling `interval` with -follow")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	options.FunctionName = flags.Arg(0)
+
+	if err := limelight.TailEdgeFunctionLogs(options, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// stringList is a flag that can be repeated.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// usageReport runs the usage-report subcommand, which exports the traffic of each
+// published hostname of an account as CSV or JSON.
+func usageReport(args []string) int {
+	flags := flag.NewFlagSet("usage-report", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s usage-report [options]\n\nOptions:\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	options := limelight.UsageReportOptions{}
+	var hostnames stringList
+	flags.StringVar(&options.Shortname, "shortname", os.Getenv("LLNW_DEFAULT_SHORTNAME"), "account `shortname` to report on (defaults to LLNW_DEFAULT_SHORTNAME)")
+	start := flags.String("start", "", "start of the time range, in RFC 3339 `time` format (required)")
+	end := flags.String("end", "", "end of the time range, in RFC 3339 `time` format (required)")
+	flags.Var(&hostnames, "hostname", "only report on this published `hostname` (can be repeated)")
+	flags.StringVar(&options.OutputFile, "output", "", "`file` to write the report to (defaults to standard output)")
+	flags.StringVar(&options.Format, "format", "", "report `format`, csv or json (defaults to the extension of -output, or csv)")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	var err error
+	if options.Start, err = time.Parse(time.RFC3339, *start); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -start must be set to a time in RFC 3339 format\n")
+		return 2
+	}
+	if options.End, err = time.Parse(time.RFC3339, *end); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -end must be set to a time in RFC 3339 format\n")
+		return 2
+	}
+	options.PublishedHostnames = hostnames
+
+	if err := limelight.ExportUsageReport(options, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	return 0
+}